@@ -83,6 +83,7 @@ func NewManager(ctx context.Context, logger *zap.Logger, version string, startTi
 	}
 
 	writeAccountIDSymlink(logger, res.AccountID)
+	lambdalifecycle.SetAccountID(res.AccountID)
 
 	var listener *telemetryapi.Listener
 	if initType != lambdalifecycle.LambdaManagedInstances {
@@ -93,7 +94,7 @@ func NewManager(ctx context.Context, logger *zap.Logger, version string, startTi
 		}
 
 		telemetryClient := telemetryapi.NewClient(logger)
-		_, err = telemetryClient.Subscribe(ctx, []telemetryapi.EventType{telemetryapi.Platform}, res.ExtensionID, addr)
+		_, err = telemetryClient.Subscribe(ctx, []telemetryapi.EventType{telemetryapi.Platform}, res.ExtensionID, telemetryapi.HTTPDestination(addr), telemetryapi.DefaultBufferingCfg, telemetryapi.SchemaVersionLatest)
 		if err != nil {
 			logger.Fatal("Cannot register Telemetry API client", zap.Error(err))
 		}
@@ -168,6 +169,7 @@ func (lm *manager) processEvents(ctx context.Context) error {
 				}
 				return err
 			} else if lm.listener != nil && res.EventType == extensionapi.Invoke {
+				lambdalifecycle.SetInvokedFunctionARN(res.InvokedFunctionArn)
 				lm.notifyFunctionInvoked()
 
 				err = lm.listener.Wait(ctx, res.RequestID)
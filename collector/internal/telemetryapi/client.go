@@ -49,28 +49,20 @@ func NewClient(logger *zap.Logger) *Client {
 	}
 }
 
-func (c *Client) Subscribe(ctx context.Context, eventTypes []EventType, extensionID string, listenerURI string) (string, error) {
-	bufferingConfig := BufferingCfg{
-		MaxItems:  1000,
-		MaxBytes:  256 * 1024,
-		TimeoutMS: 25,
-	}
-
-	destination := Destination{
-		Protocol:   HttpProto,
-		HttpMethod: HttpPost,
-		Encoding:   JSON,
-		URI:        URI(listenerURI),
-	}
-
-	data, err := json.Marshal(
-		&SubscribeRequest{
-			SchemaVersion: SchemaVersionLatest,
-			EventTypes:    eventTypes,
-			BufferingCfg:  bufferingConfig,
-			Destination:   destination,
-		})
+// Subscribe subscribes the listener at the destination, see HTTPDestination and TCPDestination, to the event
+// types with the given buffering and schema version.
+func (c *Client) Subscribe(ctx context.Context, eventTypes []EventType, extensionID string, destination Destination, buffering BufferingCfg, schemaVersion SchemaVersion) (string, error) {
+	return c.SendSubscribeRequest(ctx, extensionID, &SubscribeRequest{
+		SchemaVersion: schemaVersion,
+		EventTypes:    eventTypes,
+		BufferingCfg:  buffering,
+		Destination:   destination,
+	})
+}
 
+// SendSubscribeRequest subscribes the extension with the given request and returns the body of the response.
+func (c *Client) SendSubscribeRequest(ctx context.Context, extensionID string, request *SubscribeRequest) (string, error) {
+	data, err := json.Marshal(request)
 	if err != nil {
 		return "", fmt.Errorf("Failed to marshal SubscribeRequest: %w", err)
 	}
@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSubscribe(t *testing.T) {
+	testCases := []struct {
+		name        string
+		destination Destination
+	}{
+		{
+			name:        "http",
+			destination: HTTPDestination("http://sandbox:4325/"),
+		},
+		{
+			name:        "tcp",
+			destination: TCPDestination(4325),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			var received SubscribeRequest
+			var extensionID string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				extensionID = req.Header.Get(lambdaAgentIdentifierHeaderKey)
+				require.NoError(t, json.NewDecoder(req.Body).Decode(&received))
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+			t.Setenv("AWS_LAMBDA_RUNTIME_API", strings.TrimPrefix(server.URL, "http://"))
+
+			buffering := BufferingCfg{MaxItems: 5000, MaxBytes: 524288, TimeoutMS: 500}
+			client := NewClient(zap.NewNop())
+			_, err := client.Subscribe(context.Background(), []EventType{Platform}, "extension-id", test.destination, buffering, SchemaVersion20220701)
+			require.NoError(t, err)
+
+			require.Equal(t, "extension-id", extensionID)
+			require.Equal(t, SubscribeRequest{
+				SchemaVersion: SchemaVersion20220701,
+				EventTypes:    []EventType{Platform},
+				BufferingCfg:  buffering,
+				Destination:   test.destination,
+			}, received)
+		})
+	}
+}
+
+func TestDestination(t *testing.T) {
+	require.Equal(t, Destination{
+		Protocol:   HttpProto,
+		HttpMethod: HttpPost,
+		Encoding:   JSON,
+		URI:        "http://sandbox:4325/",
+	}, HTTPDestination("http://sandbox:4325/"))
+
+	data, err := json.Marshal(TCPDestination(4325))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"protocol":"TCP","port":4325}`, string(data))
+}
@@ -35,6 +35,7 @@ const (
 // Listener is used to listen to the Telemetry API
 type Listener struct {
 	httpServer *http.Server
+	tcpServer  *NDJSONServer
 	logger     *zap.Logger
 	// queue is a synchronous queue and is used to put the received log events to be dispatched later
 	queue *queue.Queue
@@ -93,6 +94,32 @@ func (s *Listener) Start() (string, error) {
 	return fmt.Sprintf("http://%s/", address), nil
 }
 
+// StartTCP starts accepting the connections the Telemetry API streams the log events on, and returns the port
+// to subscribe with, see TCPDestination.
+func (s *Listener) StartTCP() (uint16, error) {
+	listener, address, err := s.bindListener()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find available port: %w", err)
+	}
+	s.logger.Info("Listening for connections", zap.String("address", address))
+	s.tcpServer = ServeNDJSON(listener, s.logger, s.putLines)
+	return uint16(listener.Addr().(*net.TCPAddr).Port), nil
+}
+
+// putLines puts the log events streamed on a connection into the queue as they arrive.
+func (s *Listener) putLines(lines [][]byte) {
+	for _, line := range lines {
+		var el Event
+		if err := json.Unmarshal(line, &el); err != nil {
+			s.logger.Error("error unmarshalling event", zap.Error(err))
+			continue
+		}
+		if err := s.queue.Put(el); err != nil {
+			s.logger.Error("Failed to put event in queue", zap.Error(err))
+		}
+	}
+}
+
 // httpHandler handles the requests coming from the Telemetry API.
 // Everytime Telemetry API sends log events, this function will read them from the response body
 // and put into a synchronous queue to be dispatched later.
@@ -120,8 +147,14 @@ func (s *Listener) httpHandler(w http.ResponseWriter, r *http.Request) {
 	slice = nil
 }
 
-// Shutdown the HTTP server listening for logs
+// Shutdown the HTTP server or TCP listener listening for logs
 func (s *Listener) Shutdown() {
+	if s.tcpServer != nil {
+		if err := s.tcpServer.Close(); err != nil {
+			s.logger.Error("Failed to close TCP listener", zap.Error(err))
+		}
+		s.tcpServer = nil
+	}
 	if s.httpServer != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 		defer cancel()
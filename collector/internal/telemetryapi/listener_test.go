@@ -18,8 +18,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -263,6 +265,37 @@ func TestListener_httpHandler_InvalidJSON(t *testing.T) {
 	require.Equal(t, listener.queue.Len(), int64(0), "Queue should be empty after invalid JSON")
 }
 
+func TestListener_StartTCP(t *testing.T) {
+	withEnv(t, "AWS_SAM_LOCAL", "true")
+	eventBuilder := NewTestEventBuilder("test-request")
+	listener := NewListener(zaptest.NewLogger(t))
+
+	port, err := listener.StartTCP()
+	require.NoError(t, err)
+	defer listener.Shutdown()
+
+	conn, err := net.Dial("tcp", net.JoinHostPort("localhost", strconv.Itoa(int(port))))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var stream bytes.Buffer
+	for _, el := range []Event{eventBuilder.PlatformStart(), eventBuilder.FunctionLog("INFO", "Received request")} {
+		line, err := json.Marshal(el)
+		require.NoError(t, err)
+		stream.Write(append(line, '\n'))
+	}
+	stream.WriteString("{\"invalid\": json}\n")
+	_, err = conn.Write(stream.Bytes())
+	require.NoError(t, err)
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		require.Equal(c, int64(2), listener.queue.Len())
+	}, 1*time.Second, 50*time.Millisecond)
+
+	listener.Shutdown()
+	require.Nil(t, listener.tcpServer, "tcpServer should be nil after Shutdown()")
+}
+
 func TestListener_Wait_Success(t *testing.T) {
 	eventBuilder := NewTestEventBuilder("target-request")
 
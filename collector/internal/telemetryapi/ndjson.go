@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapi
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// ndjsonReadBufferSize matches the largest batch the Telemetry API buffers.
+const ndjsonReadBufferSize = MaxBufferingMaxBytes
+
+// ReadNDJSON reads a newline-delimited JSON stream until it ends, calling handle with the lines received
+// together, e.g. the events of a batch flushed by the Telemetry API. Empty lines are skipped.
+func ReadNDJSON(r io.Reader, handle func(lines [][]byte)) error {
+	reader := bufio.NewReaderSize(r, ndjsonReadBufferSize)
+	var lines [][]byte
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+		if err != nil {
+			if len(lines) > 0 {
+				handle(lines)
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		// Hand the lines over once no more data is immediately available, rather than line by line.
+		if reader.Buffered() == 0 && len(lines) > 0 {
+			handle(lines)
+			lines = nil
+		}
+	}
+}
+
+// NDJSONServer serves the connections the Telemetry API streams newline-delimited events on, for subscriptions
+// with the TCP protocol.
+type NDJSONServer struct {
+	listener net.Listener
+	logger   *zap.Logger
+	handle   func(lines [][]byte)
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{} // open connections, nil once the server is closed
+	wg       sync.WaitGroup
+}
+
+// ServeNDJSON accepts connections on the listener until the server is closed, calling handle with the lines
+// read on each of them as ReadNDJSON does. handle may be called concurrently for different connections.
+func ServeNDJSON(listener net.Listener, logger *zap.Logger, handle func(lines [][]byte)) *NDJSONServer {
+	s := &NDJSONServer{
+		listener: listener,
+		logger:   logger,
+		handle:   handle,
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.accept()
+	return s
+}
+
+func (s *NDJSONServer) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.logger.Error("Unexpected stop on TCP listener", zap.Error(err))
+			}
+			return
+		}
+
+		s.mu.Lock()
+		if s.conns == nil {
+			// The server is closing.
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serve(conn)
+	}
+}
+
+func (s *NDJSONServer) serve(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	if err := ReadNDJSON(conn, s.handle); err != nil && !errors.Is(err, net.ErrClosed) {
+		s.logger.Error("error reading connection", zap.Error(err))
+	}
+}
+
+// Close stops accepting connections, closes the open ones and waits for their handlers to return. The events
+// not read yet are dropped.
+func (s *NDJSONServer) Close() error {
+	s.mu.Lock()
+	err := s.listener.Close()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.conns = nil
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
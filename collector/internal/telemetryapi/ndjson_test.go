@@ -0,0 +1,123 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapi
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReadNDJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		stream   io.Reader
+		expected [][]string
+	}{
+		{
+			name:     "single batch",
+			stream:   strings.NewReader("{\"a\":1}\n{\"b\":2}\n"),
+			expected: [][]string{{`{"a":1}`, `{"b":2}`}},
+		},
+		{
+			name:     "missing trailing newline and empty lines",
+			stream:   strings.NewReader("{\"a\":1}\n\r\n\n{\"b\":2}"),
+			expected: [][]string{{`{"a":1}`, `{"b":2}`}},
+		},
+		{
+			name:     "separate writes",
+			stream:   io.MultiReader(strings.NewReader("{\"a\":1}\n"), strings.NewReader("{\"b\":2}\n")),
+			expected: [][]string{{`{"a":1}`}, {`{"b":2}`}},
+		},
+		{
+			name:   "empty stream",
+			stream: strings.NewReader(""),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			var batches [][]string
+			err := ReadNDJSON(test.stream, func(lines [][]byte) {
+				batch := make([]string, 0, len(lines))
+				for _, line := range lines {
+					batch = append(batch, string(line))
+				}
+				batches = append(batches, batch)
+			})
+			require.NoError(t, err)
+			require.Equal(t, test.expected, batches)
+		})
+	}
+}
+
+func TestReadNDJSONError(t *testing.T) {
+	readErr := errors.New("connection reset")
+	var lines [][]byte
+	err := ReadNDJSON(io.MultiReader(strings.NewReader("{\"a\":1}"), &errReader{readErr}), func(l [][]byte) {
+		lines = append(lines, l...)
+	})
+	require.ErrorIs(t, err, readErr)
+	require.Equal(t, [][]byte{[]byte(`{"a":1}`)}, lines)
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestServeNDJSON(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	var mu sync.Mutex
+	var lines []string
+	server := ServeNDJSON(listener, zap.NewNop(), func(batch [][]byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, line := range batch {
+			lines = append(lines, string(line))
+		}
+	})
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("{\"a\":1}\n"))
+	require.NoError(t, err)
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(c, []string{`{"a":1}`}, lines)
+	}, time.Second, 10*time.Millisecond)
+
+	// Closing the server closes the open connections.
+	require.NoError(t, server.Close())
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	require.ErrorIs(t, err, io.EOF)
+	_, err = net.Dial("tcp", listener.Addr().String())
+	require.Error(t, err)
+}
@@ -57,10 +57,30 @@ type BufferingCfg struct {
 	MaxItems uint32 `json:"maxItems"`
 	// Maximum size in bytes of the log events to be buffered in memory. (default: 262144, minimum: 262144, maximum: 1048576)
 	MaxBytes uint32 `json:"maxBytes"`
-	// Maximum time (in milliseconds) for a batch to be buffered. (default: 1000, minimum: 100, maximum: 30000)
+	// Maximum time (in milliseconds) for a batch to be buffered. (default: 1000, minimum: 25, maximum: 30000)
+	// The Telemetry API accepts shorter timeouts than the 100 ms minimum of the Logs API.
+	// See https://docs.aws.amazon.com/lambda/latest/dg/telemetry-api.html
 	TimeoutMS uint32 `json:"timeoutMs"`
 }
 
+// Ranges of the BufferingCfg fields accepted by the Telemetry API.
+const (
+	MinBufferingMaxItems  = 1000
+	MaxBufferingMaxItems  = 10000
+	MinBufferingMaxBytes  = 262144
+	MaxBufferingMaxBytes  = 1048576
+	MinBufferingTimeoutMS = 25
+	MaxBufferingTimeoutMS = 30000
+)
+
+// DefaultBufferingCfg is the buffering of the lifecycle manager's subscription and of receivers that do not configure
+// it: the smallest batches, delivered as soon as possible.
+var DefaultBufferingCfg = BufferingCfg{
+	MaxItems:  1000,
+	MaxBytes:  256 * 1024,
+	TimeoutMS: 25,
+}
+
 // URI is used to set the endpoint where the logs will be sent to
 type URI string
 
@@ -79,6 +99,8 @@ type HTTPProtocol string
 
 const (
 	HttpProto HTTPProtocol = "HTTP"
+	// TcpProto streams the events to the listener's port as newline-delimited JSON (NDJSON), one event per line.
+	TcpProto HTTPProtocol = "TCP"
 )
 
 // Denotes what the content is encoded in
@@ -88,12 +110,33 @@ const (
 	JSON HTTPEncoding = "JSON"
 )
 
-// Configuration for listeners that would like to receive telemetry via HTTP
+// Configuration for listeners that would like to receive telemetry via HTTP or TCP.
+// HTTP destinations set the URI, method and encoding, TCP destinations only the port.
 type Destination struct {
 	Protocol   HTTPProtocol `json:"protocol"`
-	URI        URI          `json:"URI"`
-	HttpMethod HTTPMethod   `json:"method"`
-	Encoding   HTTPEncoding `json:"encoding"`
+	URI        URI          `json:"URI,omitempty"`
+	Port       uint16       `json:"port,omitempty"`
+	HttpMethod HTTPMethod   `json:"method,omitempty"`
+	Encoding   HTTPEncoding `json:"encoding,omitempty"`
+}
+
+// HTTPDestination returns the destination posting the events as JSON arrays to the listener's URI.
+func HTTPDestination(uri string) Destination {
+	return Destination{
+		Protocol:   HttpProto,
+		HttpMethod: HttpPost,
+		Encoding:   JSON,
+		URI:        URI(uri),
+	}
+}
+
+// TCPDestination returns the destination streaming the events to the listener's port. Lambda connects to the
+// port on the sandbox's address, the host cannot be chosen.
+func TCPDestination(port uint16) Destination {
+	return Destination{
+		Protocol: TcpProto,
+		Port:     port,
+	}
 }
 
 type SchemaVersion string
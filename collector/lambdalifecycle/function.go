@@ -0,0 +1,45 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdalifecycle
+
+import "sync/atomic"
+
+var (
+	accountID          atomic.Value
+	invokedFunctionARN atomic.Value
+)
+
+// SetAccountID sets the ID of the AWS account the function runs in, as returned when registering the extension.
+func SetAccountID(id string) {
+	accountID.Store(id)
+}
+
+// GetAccountID returns the ID of the AWS account the function runs in, or an empty string until it is known.
+func GetAccountID() string {
+	id, _ := accountID.Load().(string)
+	return id
+}
+
+// SetInvokedFunctionARN sets the ARN the function was last invoked with, as received in the invoke event.
+func SetInvokedFunctionARN(arn string) {
+	invokedFunctionARN.Store(arn)
+}
+
+// GetInvokedFunctionARN returns the ARN the function was last invoked with, or an empty string until it is invoked.
+// The ARN may be qualified with the version or alias the function was invoked through.
+func GetInvokedFunctionARN() string {
+	arn, _ := invokedFunctionARN.Load().(string)
+	return arn
+}
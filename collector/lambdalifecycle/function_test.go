@@ -0,0 +1,35 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdalifecycle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountID(t *testing.T) {
+	require.Equal(t, "", GetAccountID())
+	SetAccountID("000123456789")
+	t.Cleanup(func() { SetAccountID("") })
+	require.Equal(t, "000123456789", GetAccountID())
+}
+
+func TestInvokedFunctionARN(t *testing.T) {
+	require.Equal(t, "", GetInvokedFunctionARN())
+	SetInvokedFunctionARN("arn:aws:lambda:us-east-1:123456789012:function:my-function:live")
+	t.Cleanup(func() { SetInvokedFunctionARN("") })
+	require.Equal(t, "arn:aws:lambda:us-east-1:123456789012:function:my-function:live", GetInvokedFunctionARN())
+}
@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"context"
+	"hash/fnv"
+	"net/http"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// rejectedRequest is a Telemetry API request answered with a failure status, so that Lambda delivers it again.
+// The telemetry the next consumers failed to accept is kept, so that a redelivery only retries the consumers
+// instead of processing the events, and recording their metrics, a second time.
+type rejectedRequest struct {
+	hash     uint64
+	attempts int
+	traces   ptrace.Traces
+	logs     plog.Logs
+}
+
+func newRejectedRequest(hash uint64) *rejectedRequest {
+	return &rejectedRequest{
+		hash:   hash,
+		traces: ptrace.NewTraces(),
+		logs:   plog.NewLogs(),
+	}
+}
+
+func (rr *rejectedRequest) hasTelemetry() bool {
+	return rr.traces.SpanCount() > 0 || rr.logs.LogRecordCount() > 0
+}
+
+// addTraces keeps the traces the consumer failed to accept, unless the error is permanent.
+func (rr *rejectedRequest) addTraces(td ptrace.Traces, err error) {
+	if !consumererror.IsPermanent(err) {
+		td.ResourceSpans().MoveAndAppendTo(rr.traces.ResourceSpans())
+	}
+}
+
+// addLogs keeps the logs the consumer failed to accept, unless the error is permanent.
+func (rr *rejectedRequest) addLogs(ld plog.Logs, err error) {
+	if !consumererror.IsPermanent(err) {
+		ld.ResourceLogs().MoveAndAppendTo(rr.logs.ResourceLogs())
+	}
+}
+
+func hashBody(body []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(body)
+	return h.Sum64()
+}
+
+// reject returns the status answering a request that could not be fully processed. Without backpressure, or
+// once the request was rejected max_retries times, the request is accepted and its telemetry dropped.
+func (r *telemetryAPIReceiver) reject(rr *rejectedRequest, status int) int {
+	if !r.backpressure {
+		return http.StatusOK
+	}
+	if r.rejected != nil && r.rejected.hash == rr.hash {
+		rr.attempts = r.rejected.attempts
+	}
+	rr.attempts++
+	if rr.attempts > r.maxRetries {
+		r.logger.Warn("Dropping telemetry of a request rejected too many times", zap.Int("attempts", rr.attempts))
+		r.rejected = nil
+		return http.StatusOK
+	}
+	r.rejected = rr
+	return status
+}
+
+// retryRejected sends the telemetry kept for a redelivered request to the consumers that failed to accept it.
+func (r *telemetryAPIReceiver) retryRejected(ctx context.Context) int {
+	failed := newRejectedRequest(r.rejected.hash)
+	if r.rejected.traces.SpanCount() > 0 && r.nextTraces != nil {
+		_ = r.consumeTraces(ctx, r.rejected.traces, failed)
+	}
+	if r.rejected.logs.LogRecordCount() > 0 && r.nextLogs != nil {
+		_ = r.consumeLogs(ctx, r.rejected.logs, failed)
+	}
+	if failed.hasTelemetry() {
+		return r.reject(failed, http.StatusServiceUnavailable)
+	}
+	r.rejected = nil
+	return http.StatusOK
+}
+
+// consumeTraces sends the traces to the next consumer, keeping them in failed if the consumer fails to accept
+// them. With backpressure the consumer is given a copy, as it may modify or retain the traces it was given,
+// while the kept traces are sent again when Lambda redelivers the request.
+func (r *telemetryAPIReceiver) consumeTraces(ctx context.Context, td ptrace.Traces, failed *rejectedRequest) error {
+	sent := td
+	if r.backpressure {
+		sent = ptrace.NewTraces()
+		td.CopyTo(sent)
+	}
+	err := r.nextTraces.ConsumeTraces(ctx, sent)
+	if err != nil {
+		r.logger.Error("error receiving traces", zap.Error(err))
+		r.recordConsumerError("traces")
+		failed.addTraces(td, err)
+	}
+	return err
+}
+
+// consumeLogs sends the logs to the next consumer, keeping them in failed if the consumer fails to accept them.
+// With backpressure the consumer is given a copy, as for consumeTraces.
+func (r *telemetryAPIReceiver) consumeLogs(ctx context.Context, ld plog.Logs, failed *rejectedRequest) error {
+	sent := ld
+	if r.backpressure {
+		sent = plog.NewLogs()
+		ld.CopyTo(sent)
+	}
+	err := r.nextLogs.ConsumeLogs(ctx, sent)
+	if err != nil {
+		r.logger.Error("error receiving logs", zap.Error(err))
+		r.recordConsumerError("logs")
+		failed.addLogs(ld, err)
+	}
+	return err
+}
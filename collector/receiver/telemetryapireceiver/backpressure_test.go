@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+// flakyConsumer fails the first failures calls with err, then accepts the telemetry. With clear, it empties
+// the telemetry it fails to accept, as a consumer modifying the data it was given would.
+type flakyConsumer struct {
+	failures int
+	err      error
+	clear    bool
+	spans    int
+	records  int
+}
+
+func (c *flakyConsumer) fail() bool {
+	if c.failures > 0 {
+		c.failures--
+		return true
+	}
+	return false
+}
+
+func (c *flakyConsumer) ConsumeTraces(_ context.Context, td ptrace.Traces) error {
+	if c.fail() {
+		if c.clear {
+			td.ResourceSpans().RemoveIf(func(ptrace.ResourceSpans) bool { return true })
+		}
+		return c.err
+	}
+	c.spans += td.SpanCount()
+	return nil
+}
+
+func (c *flakyConsumer) ConsumeLogs(_ context.Context, ld plog.Logs) error {
+	if c.fail() {
+		if c.clear {
+			ld.ResourceLogs().RemoveIf(func(plog.ResourceLogs) bool { return true })
+		}
+		return c.err
+	}
+	c.records += ld.LogRecordCount()
+	return nil
+}
+
+func (c *flakyConsumer) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+const backpressureRequest = `[
+	{"time":"2022-10-12T00:03:50.000Z", "type":"platform.start", "record": {"requestId":"test-id"}},
+	{"time":"2022-10-12T00:03:50.100Z", "type":"function", "record": "hello"},
+	{"time":"2022-10-12T00:03:50.200Z", "type":"platform.runtimeDone", "record": {"requestId":"test-id", "status":"success"}},
+	{"time":"2022-10-12T00:03:50.300Z", "type":"platform.report", "record": {"requestId":"test-id", "status":"success"}}
+]`
+
+func postBody(r *telemetryAPIReceiver, body string) int {
+	w := httptest.NewRecorder()
+	r.httpHandler(w, httptest.NewRequest("POST", "http://localhost/", strings.NewReader(body)))
+	return w.Code
+}
+
+func TestBackpressure(t *testing.T) {
+	errExport := errors.New("exporter unavailable")
+
+	testCases := []struct {
+		desc             string
+		cfg              BackpressureConfig
+		failures         int
+		clear            bool
+		err              error
+		expectedStatuses []int
+		expectedSpans    int
+	}{
+		{
+			desc:             "disabled",
+			failures:         1,
+			err:              errExport,
+			expectedStatuses: []int{http.StatusOK},
+			expectedSpans:    0,
+		},
+		{
+			desc:             "retried once",
+			cfg:              BackpressureConfig{Enabled: true, MaxRetries: 3},
+			failures:         1,
+			err:              errExport,
+			expectedStatuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedSpans:    1,
+		},
+		{
+			desc:             "retried after the consumer modified the traces",
+			cfg:              BackpressureConfig{Enabled: true, MaxRetries: 3},
+			failures:         1,
+			clear:            true,
+			err:              errExport,
+			expectedStatuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedSpans:    1,
+		},
+		{
+			desc:             "retries disabled",
+			cfg:              BackpressureConfig{Enabled: true, MaxRetries: 0},
+			failures:         1,
+			err:              errExport,
+			expectedStatuses: []int{http.StatusOK},
+			expectedSpans:    0,
+		},
+		{
+			desc:             "retry budget spent",
+			cfg:              BackpressureConfig{Enabled: true, MaxRetries: 2},
+			failures:         10,
+			err:              errExport,
+			expectedStatuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			expectedSpans:    0,
+		},
+		{
+			desc:             "permanent error",
+			cfg:              BackpressureConfig{Enabled: true, MaxRetries: 3},
+			failures:         1,
+			err:              consumererror.NewPermanent(errExport),
+			expectedStatuses: []int{http.StatusOK},
+			expectedSpans:    0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := newTelemetryAPIReceiver(&Config{Backpressure: tc.cfg}, receivertest.NewNopSettings(Type))
+			require.NoError(t, err)
+			traces := &flakyConsumer{failures: tc.failures, err: tc.err, clear: tc.clear}
+			logs := &flakyConsumer{}
+			r.registerTracesConsumer(traces)
+			r.registerLogsConsumer(logs)
+
+			for i, expected := range tc.expectedStatuses {
+				require.Equal(t, expected, postBody(r, backpressureRequest), "attempt %d", i)
+			}
+			require.Equal(t, tc.expectedSpans, traces.spans)
+			// The logs were accepted on the first attempt and are not sent again.
+			require.Equal(t, 3, logs.records)
+			require.Nil(t, r.rejected)
+		})
+	}
+}
+
+func TestBackpressureMalformedRequest(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{Backpressure: BackpressureConfig{Enabled: true, MaxRetries: 1}}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusBadRequest, postBody(r, "not json"))
+	require.Equal(t, http.StatusOK, postBody(r, "not json"))
+	require.Equal(t, http.StatusBadRequest, postBody(r, "not json"))
+	require.Equal(t, http.StatusOK, postBody(r, "[]"))
+	require.Nil(t, r.rejected)
+}
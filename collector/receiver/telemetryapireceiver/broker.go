@@ -0,0 +1,331 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-lambda/collector/internal/telemetryapi"
+	"go.uber.org/zap"
+)
+
+// defaultBroker holds the subscription of the receiver instances created by the factory.
+var defaultBroker = &subscriptionBroker{}
+
+var errSubscriptionSettingsMismatch = errors.New(
+	"telemetryapi receivers share a single subscription and must have the same protocol, buffering and schema_version")
+
+// subscriptionSettings are the settings of the Telemetry API subscription, which all receiver instances must agree on.
+type subscriptionSettings struct {
+	port          int
+	protocol      string
+	buffering     telemetryapi.BufferingCfg
+	schemaVersion telemetryapi.SchemaVersion
+}
+
+// subscriptionBroker holds the extension's Telemetry API subscription, shared by all the receiver instances.
+// Lambda only keeps the latest subscription of an extension, so the broker listens once, subscribes to the union
+// of the instances' event types and fans each batch of events out to the instances subscribed to their types.
+// The instances are also handed the events they only track the state of the invocations with, see tracksState.
+type subscriptionBroker struct {
+	subscribeMu sync.Mutex // serializes the registrations, so that subscriptions are sent in order
+	mu          sync.Mutex // guards the fields below, and is held while fanning events out
+	receivers   []*telemetryAPIReceiver
+	settings    subscriptionSettings
+	address     string // address listened on
+	port        int
+	types       []telemetryapi.EventType // event types subscribed to
+	logger      *zap.Logger
+	extensionID string
+
+	httpServer *http.Server
+	tcpServer  *telemetryapi.NDJSONServer
+
+	rejectedHash uint64                  // hash of the last request answered with a failure status
+	rejectedBy   []*telemetryAPIReceiver // instances that failed to process it
+}
+
+// register adds the receiver to the instances events are fanned out to. The first instance starts the listener,
+// and the subscription is sent again whenever an instance adds event types to it. The subscription is sent
+// without holding mu, so that events keep being delivered to the other instances meanwhile.
+func (b *subscriptionBroker) register(ctx context.Context, r *telemetryAPIReceiver) error {
+	b.subscribeMu.Lock()
+	defer b.subscribeMu.Unlock()
+
+	b.mu.Lock()
+	if len(b.receivers) == 0 {
+		b.settings = r.subscriptionSettings()
+		b.logger = r.logger
+		b.extensionID = r.extensionID
+		if err := b.listen(r); err != nil {
+			b.mu.Unlock()
+			return err
+		}
+	} else if settings := r.subscriptionSettings(); !b.accepts(settings) {
+		b.mu.Unlock()
+		return errSubscriptionSettingsMismatch
+	} else if settings.port != 0 && settings.port != b.port {
+		r.logger.Warn("telemetryapi receivers share a single listener, ignoring the port of the receiver",
+			zap.Int("port", settings.port), zap.Int("listener_port", b.port))
+	}
+
+	types := slices.Clone(b.types)
+	for _, t := range r.types {
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	if len(types) == len(b.types) {
+		b.receivers = append(b.receivers, r)
+		b.mu.Unlock()
+		return nil
+	}
+	request := b.subscribeRequest(types)
+	logger, extensionID, address := b.logger, b.extensionID, b.address
+	b.mu.Unlock()
+
+	telemetryClient := telemetryapi.NewClient(logger)
+	if _, err := telemetryClient.SendSubscribeRequest(ctx, extensionID, request); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.types = types
+	b.receivers = append(b.receivers, r)
+	b.mu.Unlock()
+	logger.Info("Successfully subscribed to telemetry", zap.String("address", address), zap.Any("types", types))
+	return nil
+}
+
+// unregister stops fanning events out to the receiver. The listener is stopped with the last instance; Lambda
+// cannot be unsubscribed from, so the events of the remaining instances' former types are dropped.
+func (b *subscriptionBroker) unregister(ctx context.Context, r *telemetryAPIReceiver) error {
+	b.subscribeMu.Lock()
+	defer b.subscribeMu.Unlock()
+
+	b.mu.Lock()
+	b.remove(r)
+	if len(b.receivers) > 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	b.types = nil
+	httpServer, tcpServer := b.httpServer, b.tcpServer
+	b.httpServer, b.tcpServer = nil, nil
+	b.mu.Unlock()
+
+	// The handlers wait for mu, so the listener is stopped without holding it.
+	if tcpServer != nil {
+		// The events not read yet are dropped.
+		return tcpServer.Close()
+	}
+	if httpServer != nil {
+		return httpServer.Shutdown(ctx)
+	}
+	return nil
+}
+
+// accepts reports whether a receiver with the settings can share the subscription. The settings are compared
+// with their defaults applied. The port is not compared: all the receivers use the port of the first one's
+// listener.
+func (b *subscriptionBroker) accepts(settings subscriptionSettings) bool {
+	settings.port = b.settings.port
+	return settings == b.settings
+}
+
+// remove stops fanning events out to the receiver. The caller must hold b.mu.
+func (b *subscriptionBroker) remove(r *telemetryAPIReceiver) {
+	b.receivers = slices.DeleteFunc(b.receivers, func(other *telemetryAPIReceiver) bool { return other == r })
+	b.rejectedBy = slices.DeleteFunc(b.rejectedBy, func(other *telemetryAPIReceiver) bool { return other == r })
+}
+
+// listen starts the HTTP server or TCP listener events are received on, on the receiver's port. The caller must
+// hold b.mu.
+func (b *subscriptionBroker) listen(r *telemetryAPIReceiver) error {
+	listener, address, err := r.bindListener()
+	if err != nil {
+		return fmt.Errorf("failed to find available port: %w", err)
+	}
+	b.address = address
+	b.port = listener.Addr().(*net.TCPAddr).Port
+	b.logger.Info("Starting telemetry API listener", zap.String("address", address))
+
+	if b.settings.protocol == protocolTCP {
+		b.tcpServer = telemetryapi.ServeNDJSON(listener, b.logger, b.handleLines)
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", b.httpHandler)
+	httpServer := &http.Server{Addr: b.address, Handler: mux}
+	b.httpServer = httpServer
+	go func() {
+		err := httpServer.Serve(listener)
+		if !errors.Is(err, http.ErrServerClosed) {
+			b.logger.Error("Unexpected stop on HTTP Server", zap.Error(err))
+		} else {
+			b.logger.Info("HTTP server closed", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// subscribeRequest returns the request subscribing the listener to the event types.
+func (b *subscriptionBroker) subscribeRequest(types []telemetryapi.EventType) *telemetryapi.SubscribeRequest {
+	request := &telemetryapi.SubscribeRequest{
+		SchemaVersion: b.settings.schemaVersion,
+		EventTypes:    types,
+		BufferingCfg:  b.settings.buffering,
+		Destination:   telemetryapi.HTTPDestination(fmt.Sprintf("http://%s/", b.address)),
+	}
+	if b.settings.protocol == protocolTCP {
+		request.Destination = telemetryapi.TCPDestination(uint16(b.port))
+	}
+	return request
+}
+
+// httpHandler fans the events of a Telemetry API request out to the receiver instances, answering with the
+// highest status they returned. A redelivered request is only handed to the instances that failed to process it.
+func (b *subscriptionBroker) httpHandler(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := http.StatusOK
+	if err != nil {
+		b.logger.Error("error reading body", zap.Error(err))
+		for _, r := range b.receivers {
+			status = max(status, r.rejectUnreadable())
+		}
+		w.WriteHeader(status)
+		return
+	}
+
+	receivers := b.receivers
+	hash := hashBody(body)
+	if b.rejectedBy != nil && b.rejectedHash == hash {
+		receivers = b.rejectedBy
+	}
+	var rejectedBy []*telemetryAPIReceiver
+	for i, batch := range splitBody(body, receivers) {
+		if batch == nil {
+			continue
+		}
+		if s := receivers[i].handleBody(batch); s != http.StatusOK {
+			status = max(status, s)
+			rejectedBy = append(rejectedBy, receivers[i])
+		}
+	}
+	b.rejectedHash, b.rejectedBy = hash, rejectedBy
+	w.WriteHeader(status)
+}
+
+// eventSource returns the event type subscribed to for an event, e.g. platform for platform.start.
+func eventSource(eventType string) telemetryapi.EventType {
+	source, _, _ := strings.Cut(eventType, ".")
+	return telemetryapi.EventType(source)
+}
+
+// stateEventTypes are the platform events also handed to the receivers not subscribed to platform events, so that
+// they can attribute their logs to invocations and extensions.
+var stateEventTypes = []string{
+	string(telemetryapi.PlatformStart),
+	string(telemetryapi.PlatformRuntimeDone),
+	string(telemetryapi.PlatformReport),
+	string(telemetryapi.PlatformExtension),
+}
+
+// delivers reports whether an event of the type is handed to the receiver: the events of its types, and the events
+// it tracks the state of the invocations with.
+func delivers(r *telemetryAPIReceiver, eventType string) bool {
+	return slices.Contains(r.types, eventSource(eventType)) || tracksState(r.types, eventType)
+}
+
+// tracksState reports whether a receiver subscribed to the types tracks the events of the type without emitting
+// telemetry for them: the platform events of stateEventTypes, and the function logs whose exceptions are recorded on
+// the spans of the receivers subscribed to platform events.
+func tracksState(types []telemetryapi.EventType, eventType string) bool {
+	switch eventSource(eventType) {
+	case telemetryapi.Platform:
+		return slices.Contains(stateEventTypes, eventType)
+	case telemetryapi.Function:
+		return slices.Contains(types, telemetryapi.Platform)
+	}
+	return false
+}
+
+// eventTypeOf returns the type of a JSON event, and false if it is not an event.
+func eventTypeOf(raw []byte) (string, bool) {
+	var el struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &el); err != nil {
+		return "", false
+	}
+	return el.Type, true
+}
+
+// splitBody returns the JSON array of the events handed to each receiver, nil if there are none. A receiver
+// handed all the events is handed the body as is. A body that is not an array of events is handed to every
+// receiver, to be reported as it would be for its own subscription.
+func splitBody(body []byte, receivers []*telemetryAPIReceiver) [][]byte {
+	batches := make([][]byte, len(receivers))
+	var raws []json.RawMessage
+	if err := json.Unmarshal(body, &raws); err != nil {
+		for i := range receivers {
+			batches[i] = body
+		}
+		return batches
+	}
+	types := make([]string, len(raws))
+	for i, raw := range raws {
+		eventType, ok := eventTypeOf(raw)
+		if !ok {
+			for i := range receivers {
+				batches[i] = body
+			}
+			return batches
+		}
+		types[i] = eventType
+	}
+
+	for i, r := range receivers {
+		var selected [][]byte
+		for j, raw := range raws {
+			if delivers(r, types[j]) {
+				selected = append(selected, raw)
+			}
+		}
+		switch len(selected) {
+		case 0:
+		case len(raws):
+			batches[i] = body
+		default:
+			batches[i] = slices.Concat([]byte("["), bytes.Join(selected, []byte(",")), []byte("]"))
+		}
+	}
+	return batches
+}
@@ -0,0 +1,397 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-lambda/collector/internal/telemetryapi"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.uber.org/zap"
+)
+
+func TestSubscribeRequest(t *testing.T) {
+	httpDestination := telemetryapi.Destination{
+		Protocol:   telemetryapi.HttpProto,
+		HttpMethod: telemetryapi.HttpPost,
+		Encoding:   telemetryapi.JSON,
+		URI:        "http://localhost:4325/",
+	}
+	testCases := []struct {
+		desc     string
+		cfg      *Config
+		expected telemetryapi.SubscribeRequest
+	}{
+		{
+			desc: "defaults",
+			cfg:  &Config{Types: []string{platform}},
+			expected: telemetryapi.SubscribeRequest{
+				SchemaVersion: telemetryapi.SchemaVersionLatest,
+				EventTypes:    []telemetryapi.EventType{telemetryapi.Platform},
+				BufferingCfg:  telemetryapi.BufferingCfg{MaxItems: 1000, MaxBytes: 262144, TimeoutMS: 25},
+				Destination:   httpDestination,
+			},
+		},
+		{
+			desc: "configured",
+			cfg: &Config{
+				Types:         []string{function},
+				SchemaVersion: telemetryapi.SchemaVersion20220701,
+				Buffering:     BufferingConfig{MaxItems: 10000, TimeoutMS: 1000},
+			},
+			expected: telemetryapi.SubscribeRequest{
+				SchemaVersion: telemetryapi.SchemaVersion20220701,
+				EventTypes:    []telemetryapi.EventType{telemetryapi.Function},
+				BufferingCfg:  telemetryapi.BufferingCfg{MaxItems: 10000, MaxBytes: 262144, TimeoutMS: 1000},
+				Destination:   httpDestination,
+			},
+		},
+		{
+			desc: "tcp",
+			cfg:  &Config{Types: []string{function}, Protocol: protocolTCP},
+			expected: telemetryapi.SubscribeRequest{
+				SchemaVersion: telemetryapi.SchemaVersionLatest,
+				EventTypes:    []telemetryapi.EventType{telemetryapi.Function},
+				BufferingCfg:  telemetryapi.BufferingCfg{MaxItems: 1000, MaxBytes: 262144, TimeoutMS: 25},
+				Destination:   telemetryapi.Destination{Protocol: telemetryapi.TcpProto, Port: 4325},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := newTelemetryAPIReceiver(tc.cfg, receivertest.NewNopSettings(Type))
+			require.NoError(t, err)
+			b := &subscriptionBroker{settings: r.subscriptionSettings(), address: "localhost:4325", port: 4325}
+			require.Equal(t, &tc.expected, b.subscribeRequest(r.types))
+		})
+	}
+}
+
+// fakeTelemetryAPI records the subscribe requests sent to the Telemetry API.
+type fakeTelemetryAPI struct {
+	mu       sync.Mutex
+	requests []telemetryapi.SubscribeRequest
+}
+
+func (f *fakeTelemetryAPI) subscriptions() []telemetryapi.SubscribeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func startFakeTelemetryAPI(t *testing.T) *fakeTelemetryAPI {
+	t.Helper()
+	f := &fakeTelemetryAPI{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var request telemetryapi.SubscribeRequest
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&request))
+		f.mu.Lock()
+		f.requests = append(f.requests, request)
+		f.mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", strings.TrimPrefix(server.URL, "http://"))
+	t.Setenv("AWS_SAM_LOCAL", "true")
+	return f
+}
+
+// newBrokerReceiver returns a receiver of the types sending its logs to a sink, subscribed through b.
+func newBrokerReceiver(t *testing.T, b *subscriptionBroker, cfg *Config) (*telemetryAPIReceiver, *consumertest.LogsSink) {
+	t.Helper()
+	r, err := newTelemetryAPIReceiver(cfg, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+	r.broker = b
+	logsSink := &consumertest.LogsSink{}
+	r.registerLogsConsumer(logsSink)
+	return r, logsSink
+}
+
+func TestBrokerSharedSubscription(t *testing.T) {
+	api := startFakeTelemetryAPI(t)
+	b := &subscriptionBroker{}
+	platformReceiver, platformLogs := newBrokerReceiver(t, b, &Config{Types: []string{platform}})
+	functionReceiver, functionLogs := newBrokerReceiver(t, b, &Config{Types: []string{function}})
+	allReceiver, allLogs := newBrokerReceiver(t, b, &Config{Types: []string{platform, function}})
+
+	require.NoError(t, platformReceiver.Start(context.Background(), nil))
+	require.NoError(t, functionReceiver.Start(context.Background(), nil))
+	require.NoError(t, allReceiver.Start(context.Background(), nil))
+
+	// The third receiver adds no event type, the subscription is not sent again.
+	subscriptions := api.subscriptions()
+	require.Len(t, subscriptions, 2)
+	require.Equal(t, []telemetryapi.EventType{telemetryapi.Platform}, subscriptions[0].EventTypes)
+	require.Equal(t, []telemetryapi.EventType{telemetryapi.Platform, telemetryapi.Function}, subscriptions[1].EventTypes)
+	require.Equal(t, subscriptions[0].Destination, subscriptions[1].Destination)
+
+	body := `[
+		{"time":"2024-01-02T15:04:05.000Z","type":"platform.initStart","record":{"runtimeVersion":"nodejs:20"}},
+		{"time":"2024-01-02T15:04:05.001Z","type":"function","record":"hello"}
+	]`
+	resp, err := http.Post(string(subscriptions[1].Destination.URI), "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Equal(t, 1, platformLogs.LogRecordCount())
+	require.Equal(t, 1, functionLogs.LogRecordCount())
+	require.Equal(t, []string{"hello"}, logBodies(functionLogs.AllLogs()[0]))
+	require.Equal(t, 2, allLogs.LogRecordCount())
+
+	require.NoError(t, platformReceiver.Shutdown(context.Background()))
+	require.NoError(t, functionReceiver.Shutdown(context.Background()))
+	require.NotNil(t, b.httpServer, "the listener is kept while a receiver is registered")
+	require.NoError(t, allReceiver.Shutdown(context.Background()))
+	require.Nil(t, b.httpServer)
+	require.Empty(t, b.receivers)
+}
+
+func TestBrokerSettings(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		first       *Config
+		second      *Config
+		expectedErr error
+	}{
+		{
+			desc:   "port not set",
+			first:  &Config{Types: []string{platform}, Port: 4330},
+			second: &Config{Types: []string{function}},
+		},
+		{
+			desc:   "same port",
+			first:  &Config{Types: []string{platform}, Port: 4330},
+			second: &Config{Types: []string{function}, Port: 4330},
+		},
+		{
+			desc:   "different port",
+			first:  &Config{Types: []string{platform}, Port: 4330},
+			second: &Config{Types: []string{function}, Port: 4331},
+		},
+		{
+			desc:        "different buffering",
+			first:       &Config{Types: []string{platform}},
+			second:      &Config{Types: []string{function}, Buffering: BufferingConfig{TimeoutMS: 1000}},
+			expectedErr: errSubscriptionSettingsMismatch,
+		},
+		{
+			desc:   "explicit defaults",
+			first:  &Config{Types: []string{platform}},
+			second: &Config{Types: []string{function}, Protocol: protocolHTTP, SchemaVersion: string(telemetryapi.SchemaVersionLatest), Buffering: BufferingConfig{MaxItems: 1000, MaxBytes: 262144, TimeoutMS: 25}},
+		},
+		{
+			desc:        "different protocol",
+			first:       &Config{Types: []string{platform}},
+			second:      &Config{Types: []string{function}, Protocol: protocolTCP},
+			expectedErr: errSubscriptionSettingsMismatch,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			startFakeTelemetryAPI(t)
+			b := &subscriptionBroker{}
+			first, _ := newBrokerReceiver(t, b, tc.first)
+			second, _ := newBrokerReceiver(t, b, tc.second)
+
+			require.NoError(t, first.Start(context.Background(), nil))
+			err := second.Start(context.Background(), nil)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				require.Equal(t, []*telemetryAPIReceiver{first}, b.receivers)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, []*telemetryAPIReceiver{first, second}, b.receivers)
+				require.NoError(t, second.Shutdown(context.Background()))
+			}
+			require.NoError(t, first.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestBrokerSubscribeFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", strings.TrimPrefix(server.URL, "http://"))
+	t.Setenv("AWS_SAM_LOCAL", "true")
+	b := &subscriptionBroker{}
+	r, _ := newBrokerReceiver(t, b, &Config{Types: []string{platform}})
+
+	require.Error(t, r.Start(context.Background(), nil))
+	require.Empty(t, b.receivers)
+	require.Nil(t, b.httpServer)
+}
+
+func TestBrokerDeliversWhileSubscribing(t *testing.T) {
+	subscribing := make(chan struct{})
+	release := make(chan struct{})
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) > 1 {
+			close(subscribing)
+			<-release
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", strings.TrimPrefix(server.URL, "http://"))
+	t.Setenv("AWS_SAM_LOCAL", "true")
+	b := &subscriptionBroker{}
+	functionReceiver, functionLogs := newBrokerReceiver(t, b, &Config{Types: []string{function}})
+	platformReceiver, _ := newBrokerReceiver(t, b, &Config{Types: []string{platform}})
+	require.NoError(t, functionReceiver.Start(context.Background(), nil))
+
+	started := make(chan error, 1)
+	go func() { started <- platformReceiver.Start(context.Background(), nil) }()
+	<-subscribing
+
+	// The events of the subscribed instances are delivered while the subscription is sent.
+	w := httptest.NewRecorder()
+	b.httpHandler(w, httptest.NewRequest("POST", "http://localhost/", strings.NewReader(
+		`[{"time":"2022-10-12T00:03:50.000Z", "type":"function", "record": "hello"}]`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, 1, functionLogs.LogRecordCount())
+
+	release <- struct{}{}
+	require.NoError(t, <-started)
+	require.Len(t, b.receivers, 2)
+	require.NoError(t, platformReceiver.Shutdown(context.Background()))
+	require.NoError(t, functionReceiver.Shutdown(context.Background()))
+}
+
+func TestBrokerRedelivery(t *testing.T) {
+	failing, err := newTelemetryAPIReceiver(&Config{Types: []string{function}, Backpressure: BackpressureConfig{Enabled: true, MaxRetries: defaultBackpressureMaxRetries}}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+	flaky := &flakyConsumer{failures: 1, err: errors.New("exporter unavailable")}
+	failing.registerLogsConsumer(flaky)
+	other, otherLogs := newBrokerReceiver(t, nil, &Config{Types: []string{function}})
+	b := &subscriptionBroker{logger: zap.NewNop(), receivers: []*telemetryAPIReceiver{failing, other}}
+
+	post := func() int {
+		w := httptest.NewRecorder()
+		b.httpHandler(w, httptest.NewRequest("POST", "http://localhost/", strings.NewReader(backpressureRequest)))
+		return w.Code
+	}
+	require.Equal(t, http.StatusServiceUnavailable, post())
+	require.Equal(t, http.StatusOK, post())
+
+	// The redelivered request is only handed to the receiver that failed to process it.
+	require.Equal(t, 1, flaky.records)
+	require.Equal(t, 1, otherLogs.LogRecordCount())
+	require.Nil(t, b.rejectedBy)
+}
+
+func TestBrokerStateEvents(t *testing.T) {
+	platformReceiver, platformLogs := newBrokerReceiver(t, nil, &Config{Types: []string{platform}})
+	tracesSink := &consumertest.TracesSink{}
+	platformReceiver.registerTracesConsumer(tracesSink)
+	functionReceiver, functionLogs := newBrokerReceiver(t, nil, &Config{Types: []string{function}})
+	b := &subscriptionBroker{logger: zap.NewNop(), receivers: []*telemetryAPIReceiver{platformReceiver, functionReceiver}}
+
+	body := `[
+		{"time":"2022-10-12T00:03:50.000Z","type":"platform.start","record":{"requestId":"a","tracing":{"spanId":"3a6fd4da3b5f2d78","type":"X-Amzn-Trace-Id","value":"Root=1-5e1b4151-43a0913a12345678901234f5;Parent=53995c3f42cd8ad8;Sampled=1"}}},
+		{"time":"2022-10-12T00:03:50.010Z","type":"function","record":"hello"},
+		{"time":"2022-10-12T00:03:50.020Z","type":"function","record":{"level":"ERROR","requestId":"a","message":{"errorType":"TypeError","errorMessage":"x is undefined"}}},
+		{"time":"2022-10-12T00:03:50.100Z","type":"platform.runtimeDone","record":{"requestId":"a","status":"error","errorType":"TypeError"}},
+		{"time":"2022-10-12T00:03:50.200Z","type":"platform.report","record":{"requestId":"a","status":"error","metrics":{"durationMs":100.0}}}
+	]`
+	w := httptest.NewRecorder()
+	b.httpHandler(w, httptest.NewRequest("POST", "http://localhost/", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// The function receiver attributes its logs to the invocation and its trace without emitting platform logs.
+	records := logRecords(t, functionLogs.AllLogs()[0])
+	require.Equal(t, 2, records.Len())
+	for i := 0; i < records.Len(); i++ {
+		record := records.At(i)
+		requestID, ok := record.Attributes().Get(string(semconv.FaaSInvocationIDKey))
+		require.True(t, ok)
+		require.Equal(t, "a", requestID.Str())
+		require.Equal(t, "5e1b415143a0913a12345678901234f5", record.TraceID().String())
+		require.Equal(t, "3a6fd4da3b5f2d78", record.SpanID().String())
+	}
+	require.Equal(t, "hello", records.At(0).Body().Str())
+	require.Empty(t, functionReceiver.invocations)
+	require.Empty(t, functionReceiver.logTraceContexts)
+	require.Equal(t, "", functionReceiver.currentFaasInvocationID)
+
+	// The platform receiver records the function's exception on the invocation span without emitting function logs.
+	require.Equal(t, 2, platformLogs.LogRecordCount())
+	require.Equal(t, 1, tracesSink.SpanCount())
+	span := tracesSink.AllTraces()[0].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	require.Equal(t, 1, span.Events().Len())
+	require.Equal(t, "exception", span.Events().At(0).Name())
+}
+
+func TestSplitBody(t *testing.T) {
+	platformReceiver := &telemetryAPIReceiver{types: []telemetryapi.EventType{telemetryapi.Platform}}
+	functionReceiver := &telemetryAPIReceiver{types: []telemetryapi.EventType{telemetryapi.Function}}
+	allReceiver := &telemetryAPIReceiver{types: []telemetryapi.EventType{telemetryapi.Platform, telemetryapi.Function}}
+	receivers := []*telemetryAPIReceiver{platformReceiver, functionReceiver, allReceiver}
+
+	testCases := []struct {
+		desc     string
+		body     string
+		expected []string
+	}{
+		{
+			desc:     "mixed events",
+			body:     `[{"type":"platform.initStart"}, {"type":"function","record":"hello"}]`,
+			expected: []string{`[{"type":"platform.initStart"}, {"type":"function","record":"hello"}]`, `[{"type":"function","record":"hello"}]`, `[{"type":"platform.initStart"}, {"type":"function","record":"hello"}]`},
+		},
+		{
+			desc:     "invocation events",
+			body:     `[{"type":"platform.initStart"}, {"type":"platform.start"}, {"type":"extension","record":"hello"}]`,
+			expected: []string{`[{"type":"platform.initStart"},{"type":"platform.start"}]`, `[{"type":"platform.start"}]`, `[{"type":"platform.initStart"},{"type":"platform.start"}]`},
+		},
+		{
+			desc:     "no receiver subscribed",
+			body:     `[{"type":"extension","record":"hello"}]`,
+			expected: []string{"", "", ""},
+		},
+		{
+			desc:     "not an array of events",
+			body:     `{"type":"function"}`,
+			expected: []string{`{"type":"function"}`, `{"type":"function"}`, `{"type":"function"}`},
+		},
+		{
+			desc:     "not an event",
+			body:     `[{"type":"function"}, 42]`,
+			expected: []string{`[{"type":"function"}, 42]`, `[{"type":"function"}, 42]`, `[{"type":"function"}, 42]`},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			batches := splitBody([]byte(tc.body), receivers)
+			actual := make([]string, len(batches))
+			for i, batch := range batches {
+				actual[i] = string(batch)
+			}
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
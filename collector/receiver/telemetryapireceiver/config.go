@@ -15,18 +15,116 @@
 package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/open-telemetry/opentelemetry-lambda/collector/internal/telemetryapi"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 )
 
 // Config defines the configuration for the various elements of the receiver agent.
 type Config struct {
 	extensionID        string
-	Port               int      `mapstructure:"port"`
-	Types              []string `mapstructure:"types"`
-	LogReport          bool     `mapstructure:"log_report"`
-	MetricsTemporality string   `mapstructure:"metrics_temporality"`
-	ExportInterval     int      `mapstructure:"export_interval_ms"`
+	Port               int                     `mapstructure:"port"`
+	Protocol           string                  `mapstructure:"protocol"`
+	Types              []string                `mapstructure:"types"`
+	Buffering          BufferingConfig         `mapstructure:"buffering"`
+	SchemaVersion      string                  `mapstructure:"schema_version"`
+	Backpressure       BackpressureConfig      `mapstructure:"backpressure"`
+	LogReport          bool                    `mapstructure:"log_report"`
+	PlatformLogFormat  string                  `mapstructure:"platform_log_format"`
+	MetricsTemporality string                  `mapstructure:"metrics_temporality"`
+	ExportInterval     int                     `mapstructure:"export_interval_ms"`
+	IDGeneration       string                  `mapstructure:"id_generation"`
+	Cost               CostConfig              `mapstructure:"cost"`
+	Metrics            map[string]MetricConfig `mapstructure:"metrics"`
+	TextLogFormat      string                  `mapstructure:"text_log_format"`
+	Multiline          *MultilineConfig        `mapstructure:"multiline"`
+	EMF                EMFConfig               `mapstructure:"emf"`
+	LogSeverityMetrics bool                    `mapstructure:"log_severity_metrics"`
+	LogSources         []string                `mapstructure:"log_sources"`
+}
+
+// EMFConfig defines the extraction of metrics from function logs in the CloudWatch Embedded Metric Format.
+type EMFConfig struct {
+	// Enabled converts the metrics of EMF log records into gauges on the metrics pipeline.
+	Enabled bool `mapstructure:"enabled"`
+	// DropLogs drops the log records metrics were extracted from instead of passing them through.
+	DropLogs bool `mapstructure:"drop_logs"`
+}
+
+// MultilineConfig defines how consecutive plain text function log lines, such as the lines of a stack
+// trace, are joined into a single log record.
+type MultilineConfig struct {
+	// Preset selects the continuation pattern of the stack traces of a runtime: `java`, `python` or `nodejs`.
+	Preset string `mapstructure:"preset"`
+	// StartPattern matches the lines starting a new record. Lines not matching it continue the previous
+	// record unless a continuation pattern is set.
+	StartPattern string `mapstructure:"start_pattern"`
+	// ContinuationPattern matches the lines continuing the previous record.
+	ContinuationPattern string `mapstructure:"continuation_pattern"`
+	// MaxLines is the maximum number of lines joined into a single record. Defaults to 500.
+	MaxLines int `mapstructure:"max_lines"`
+	// FlushTimeoutMS is the time in milliseconds after which a record no line was joined to is completed, when
+	// the end of the invocation is not received first. Defaults to 1000.
+	FlushTimeoutMS int `mapstructure:"flush_timeout_ms"`
+}
+
+// BufferingConfig defines how the Telemetry API batches events before sending them to the receiver.
+// A batch is sent as soon as one of the limits is reached. Unset fields keep the receiver's defaults.
+type BufferingConfig struct {
+	// MaxItems is the maximum number of events in a batch, between 1000 and 10000. Defaults to 1000.
+	MaxItems int `mapstructure:"max_items"`
+	// MaxBytes is the maximum size of a batch in bytes, between 262144 and 1048576. Defaults to 262144.
+	MaxBytes int `mapstructure:"max_bytes"`
+	// TimeoutMS is the maximum time in milliseconds events are buffered, between 25 and 30000. Defaults to 25.
+	TimeoutMS int `mapstructure:"timeout_ms"`
+}
+
+// BackpressureConfig defines how the receiver signals the failures to process a request back to Lambda.
+type BackpressureConfig struct {
+	// Enabled answers the requests that could not be read, parsed or exported with a non-2xx status,
+	// so that Lambda delivers them again.
+	Enabled bool `mapstructure:"enabled"`
+	// MaxRetries is the number of times a request is rejected before its telemetry is dropped. Defaults to 3.
+	MaxRetries int `mapstructure:"max_retries"`
+}
+
+// MetricConfig defines the dimensions attached to one of the FaaS metrics.
+type MetricConfig struct {
+	// Dimensions lists the attributes attached to the metric's data points.
+	Dimensions []string `mapstructure:"dimensions"`
+	// MaxSeries is the maximum number of series recorded for the metric. Further series are aggregated
+	// into a single series with the `otel.metric.overflow` attribute. Defaults to 100 when dimensions are set.
+	MaxSeries int `mapstructure:"max_series"`
+	// Buckets overrides the explicit bucket bounds of a histogram metric.
+	Buckets []float64 `mapstructure:"buckets"`
+	// Exponential switches a histogram metric to base-2 exponential buckets.
+	Exponential *ExponentialHistogramConfig `mapstructure:"exponential"`
+}
+
+// ExponentialHistogramConfig defines the resolution of an exponential histogram.
+type ExponentialHistogramConfig struct {
+	// MaxSize is the maximum number of buckets of each of the positive and negative ranges. Defaults to 160.
+	MaxSize int `mapstructure:"max_size"`
+	// MaxScale is the scale histograms start at before downscaling to fit MaxSize. Defaults to 20.
+	MaxScale *int `mapstructure:"max_scale"`
+}
+
+// CostConfig defines the prices used to estimate the cost of the function's invocations.
+type CostConfig struct {
+	// Prices holds the prices for each instruction set architecture, keyed by `x86_64` or `arm64`.
+	// The estimated cost metric is only recorded when prices are set for the architecture the function runs on.
+	Prices map[string]ArchitecturePrices `mapstructure:"prices"`
+}
+
+// ArchitecturePrices defines the Lambda prices for a single architecture.
+type ArchitecturePrices struct {
+	GBSecond float64 `mapstructure:"gb_second"`
+	Request  float64 `mapstructure:"request"`
 }
 
 // Validate validates the configuration by checking for missing or invalid fields
@@ -39,11 +137,163 @@ func (cfg *Config) Validate() error {
 			return fmt.Errorf("unknown extension type: %s", t)
 		}
 	}
+	for _, s := range cfg.LogSources {
+		if s != platform && s != function && s != extension {
+			return fmt.Errorf("unknown log source: %s", s)
+		}
+	}
+	switch cfg.Protocol {
+	case "", protocolHTTP:
+	case protocolTCP:
+		if cfg.Backpressure.Enabled {
+			return errors.New("backpressure requires the http protocol")
+		}
+	default:
+		return fmt.Errorf("unknown protocol: %s", cfg.Protocol)
+	}
+	if err := cfg.Buffering.validate(); err != nil {
+		return err
+	}
+	if cfg.Backpressure.MaxRetries < 0 {
+		return fmt.Errorf("backpressure max_retries must be non-negative: %d", cfg.Backpressure.MaxRetries)
+	}
+	switch cfg.SchemaVersion {
+	case "", telemetryapi.SchemaVersion20220701, telemetryapi.SchemaVersion20221213:
+	default:
+		return fmt.Errorf("unknown schema version: %s", cfg.SchemaVersion)
+	}
 	if cfg.MetricsTemporality != "" {
 		temporality := strings.ToLower(cfg.MetricsTemporality)
 		if temporality != "delta" && temporality != "cumulative" {
 			return fmt.Errorf("unknown metrics temporality: %s", cfg.MetricsTemporality)
 		}
 	}
+	if cfg.IDGeneration != "" && cfg.IDGeneration != idGenerationRandom && cfg.IDGeneration != idGenerationDeterministic {
+		return fmt.Errorf("unknown id generation: %s", cfg.IDGeneration)
+	}
+	switch cfg.PlatformLogFormat {
+	case "", platformLogFormatText, platformLogFormatStructured, platformLogFormatStructuredNoBody:
+	default:
+		return fmt.Errorf("unknown platform log format: %s", cfg.PlatformLogFormat)
+	}
+	switch cfg.TextLogFormat {
+	case "", textLogFormatAuto, textLogFormatNone, textLogFormatNodejs, textLogFormatPython, textLogFormatJava:
+	default:
+		return fmt.Errorf("unknown text log format: %s", cfg.TextLogFormat)
+	}
+	if cfg.EMF.DropLogs && !cfg.EMF.Enabled {
+		return errors.New("emf drop_logs requires emf to be enabled")
+	}
+	if cfg.Multiline != nil {
+		if err := cfg.Multiline.validate(); err != nil {
+			return err
+		}
+	}
+
+	builders := NewFaaSMetricBuilders(0, pmetric.AggregationTemporalityCumulative).byName()
+	for name, mc := range cfg.Metrics {
+		if _, ok := builders[name]; !ok {
+			return fmt.Errorf("unknown metric: %s", name)
+		}
+		for _, d := range mc.Dimensions {
+			if !slices.Contains(supportedDimensions, d) {
+				return fmt.Errorf("unsupported dimension for %s: %s", name, d)
+			}
+		}
+		if mc.MaxSeries < 0 {
+			return fmt.Errorf("max_series for %s must be non-negative: %d", name, mc.MaxSeries)
+		}
+		if err := validateHistogramConfig(name, mc, builders[name]); err != nil {
+			return err
+		}
+	}
+	for arch, prices := range cfg.Cost.Prices {
+		if arch != architectureX86 && arch != architectureArm {
+			return fmt.Errorf("unknown architecture in cost prices: %s", arch)
+		}
+		if prices.GBSecond < 0 || prices.Request < 0 {
+			return fmt.Errorf("cost prices for %s must be non-negative", arch)
+		}
+	}
+	return nil
+}
+
+func (bc *BufferingConfig) validate() error {
+	if bc.MaxItems != 0 && (bc.MaxItems < telemetryapi.MinBufferingMaxItems || bc.MaxItems > telemetryapi.MaxBufferingMaxItems) {
+		return fmt.Errorf("buffering max_items must be between %d and %d: %d", telemetryapi.MinBufferingMaxItems, telemetryapi.MaxBufferingMaxItems, bc.MaxItems)
+	}
+	if bc.MaxBytes != 0 && (bc.MaxBytes < telemetryapi.MinBufferingMaxBytes || bc.MaxBytes > telemetryapi.MaxBufferingMaxBytes) {
+		return fmt.Errorf("buffering max_bytes must be between %d and %d: %d", telemetryapi.MinBufferingMaxBytes, telemetryapi.MaxBufferingMaxBytes, bc.MaxBytes)
+	}
+	if bc.TimeoutMS != 0 && (bc.TimeoutMS < telemetryapi.MinBufferingTimeoutMS || bc.TimeoutMS > telemetryapi.MaxBufferingTimeoutMS) {
+		return fmt.Errorf("buffering timeout_ms must be between %d and %d: %d", telemetryapi.MinBufferingTimeoutMS, telemetryapi.MaxBufferingTimeoutMS, bc.TimeoutMS)
+	}
+	return nil
+}
+
+// bufferingCfg returns the buffering requested on subscription, with the defaults for the unset fields.
+func (bc *BufferingConfig) bufferingCfg() telemetryapi.BufferingCfg {
+	buffering := telemetryapi.DefaultBufferingCfg
+	if bc.MaxItems != 0 {
+		buffering.MaxItems = uint32(bc.MaxItems)
+	}
+	if bc.MaxBytes != 0 {
+		buffering.MaxBytes = uint32(bc.MaxBytes)
+	}
+	if bc.TimeoutMS != 0 {
+		buffering.TimeoutMS = uint32(bc.TimeoutMS)
+	}
+	return buffering
+}
+
+func (mc *MultilineConfig) validate() error {
+	if mc.Preset != "" {
+		if _, ok := multilinePresets[mc.Preset]; !ok {
+			return fmt.Errorf("unknown multiline preset: %s", mc.Preset)
+		}
+		if mc.ContinuationPattern != "" {
+			return errors.New("multiline preset and continuation_pattern are mutually exclusive")
+		}
+	} else if mc.StartPattern == "" && mc.ContinuationPattern == "" {
+		return errors.New("multiline requires a preset, start_pattern or continuation_pattern")
+	}
+	if _, err := regexp.Compile(mc.StartPattern); err != nil {
+		return fmt.Errorf("invalid multiline start_pattern: %w", err)
+	}
+	if _, err := regexp.Compile(mc.ContinuationPattern); err != nil {
+		return fmt.Errorf("invalid multiline continuation_pattern: %w", err)
+	}
+	if mc.MaxLines < 0 {
+		return fmt.Errorf("multiline max_lines must be non-negative: %d", mc.MaxLines)
+	}
+	if mc.FlushTimeoutMS < 0 {
+		return fmt.Errorf("multiline flush_timeout_ms must be non-negative: %d", mc.FlushTimeoutMS)
+	}
+	return nil
+}
+
+func validateHistogramConfig(name string, mc MetricConfig, builder dimensionsSetter) error {
+	if len(mc.Buckets) == 0 && mc.Exponential == nil {
+		return nil
+	}
+	if _, ok := builder.(*HistogramMetricBuilder); !ok {
+		return fmt.Errorf("%s is not a histogram", name)
+	}
+	if len(mc.Buckets) > 0 && mc.Exponential != nil {
+		return fmt.Errorf("buckets and exponential are mutually exclusive for %s", name)
+	}
+	for i := 1; i < len(mc.Buckets); i++ {
+		if mc.Buckets[i] <= mc.Buckets[i-1] {
+			return fmt.Errorf("buckets for %s must be strictly increasing", name)
+		}
+	}
+	if exp := mc.Exponential; exp != nil {
+		if exp.MaxSize != 0 && exp.MaxSize < 2 {
+			return fmt.Errorf("exponential max_size for %s must be at least 2: %d", name, exp.MaxSize)
+		}
+		if exp.MaxScale != nil && (*exp.MaxScale < MinExponentialHistogramScale || *exp.MaxScale > MaxExponentialHistogramScale) {
+			return fmt.Errorf("exponential max_scale for %s must be between %d and %d: %d", name, MinExponentialHistogramScale, MaxExponentialHistogramScale, *exp.MaxScale)
+		}
+	}
 	return nil
 }
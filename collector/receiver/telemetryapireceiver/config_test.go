@@ -35,6 +35,7 @@ func TestLoadConfig(t *testing.T) {
 			Port:           12345,
 			Types:          types,
 			ExportInterval: defaultExportInterval,
+			Backpressure:   BackpressureConfig{MaxRetries: defaultBackpressureMaxRetries},
 		}
 	}
 
@@ -98,6 +99,91 @@ func TestLoadConfig(t *testing.T) {
 			id:       component.NewIDWithName(component.MustNewType("telemetryapi"), "10"),
 			expected: createExpectedConfig([]string{function, extension}),
 		},
+		{
+			name: "cost prices",
+			id:   component.NewIDWithName(component.MustNewType("telemetryapi"), "11"),
+			expected: func() *Config {
+				cfg := createExpectedConfig([]string{platform, function, extension})
+				cfg.Cost = CostConfig{
+					Prices: map[string]ArchitecturePrices{
+						"x86_64": {GBSecond: 0.0000166667, Request: 0.0000002},
+						"arm64":  {GBSecond: 0.0000133334, Request: 0.0000002},
+					},
+				}
+				return cfg
+			}(),
+		},
+		{
+			name: "metric dimensions",
+			id:   component.NewIDWithName(component.MustNewType("telemetryapi"), "12"),
+			expected: func() *Config {
+				cfg := createExpectedConfig([]string{platform, function, extension})
+				cfg.Metrics = map[string]MetricConfig{
+					"faas.errors":          {Dimensions: []string{"error.type", "faas.coldstart"}},
+					"faas.invoke_duration": {Dimensions: []string{"faas.version"}, MaxSeries: 20},
+				}
+				return cfg
+			}(),
+		},
+		{
+			name: "histogram buckets",
+			id:   component.NewIDWithName(component.MustNewType("telemetryapi"), "13"),
+			expected: func() *Config {
+				cfg := createExpectedConfig([]string{platform, function, extension})
+				maxScale := 10
+				cfg.Metrics = map[string]MetricConfig{
+					"faas.init_duration":   {Buckets: []float64{0.25, 0.5, 1, 1.5, 2, 3}},
+					"faas.invoke_duration": {Exponential: &ExponentialHistogramConfig{MaxSize: 80, MaxScale: &maxScale}},
+				}
+				return cfg
+			}(),
+		},
+		{
+			name: "multiline",
+			id:   component.NewIDWithName(component.MustNewType("telemetryapi"), "14"),
+			expected: func() *Config {
+				cfg := createExpectedConfig([]string{platform, function, extension})
+				cfg.Multiline = &MultilineConfig{StartPattern: `^\d{4}-\d{2}-\d{2}`, MaxLines: 100, FlushTimeoutMS: 500}
+				return cfg
+			}(),
+		},
+		{
+			name: "buffering and schema version",
+			id:   component.NewIDWithName(component.MustNewType("telemetryapi"), "15"),
+			expected: func() *Config {
+				cfg := createExpectedConfig([]string{platform, function, extension})
+				cfg.SchemaVersion = "2022-07-01"
+				cfg.Buffering = BufferingConfig{MaxItems: 10000, MaxBytes: 1048576, TimeoutMS: 1000}
+				return cfg
+			}(),
+		},
+		{
+			name: "tcp protocol",
+			id:   component.NewIDWithName(component.MustNewType("telemetryapi"), "16"),
+			expected: func() *Config {
+				cfg := createExpectedConfig([]string{platform, function, extension})
+				cfg.Protocol = "tcp"
+				return cfg
+			}(),
+		},
+		{
+			name: "log sources",
+			id:   component.NewIDWithName(component.MustNewType("telemetryapi"), "17"),
+			expected: func() *Config {
+				cfg := createExpectedConfig([]string{platform, function, extension})
+				cfg.LogSources = []string{function, extension}
+				return cfg
+			}(),
+		},
+		{
+			name: "backpressure without retries",
+			id:   component.NewIDWithName(component.MustNewType("telemetryapi"), "18"),
+			expected: func() *Config {
+				cfg := createExpectedConfig([]string{platform, function, extension})
+				cfg.Backpressure = BackpressureConfig{Enabled: true, MaxRetries: 0}
+				return cfg
+			}(),
+		},
 	}
 
 	for _, tt := range tests {
@@ -136,6 +222,321 @@ func TestValidate(t *testing.T) {
 			},
 			expectedErr: fmt.Errorf("unknown extension type: invalid"),
 		},
+		{
+			desc: "deterministic id generation",
+			cfg: &Config{
+				IDGeneration: idGenerationDeterministic,
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "cost prices",
+			cfg: &Config{
+				Cost: CostConfig{
+					Prices: map[string]ArchitecturePrices{
+						"x86_64": {GBSecond: 0.0000166667, Request: 0.0000002},
+						"arm64":  {GBSecond: 0.0000133334, Request: 0.0000002},
+					},
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "cost prices with unknown architecture",
+			cfg: &Config{
+				Cost: CostConfig{
+					Prices: map[string]ArchitecturePrices{
+						"riscv": {GBSecond: 0.00001},
+					},
+				},
+			},
+			expectedErr: fmt.Errorf("unknown architecture in cost prices: riscv"),
+		},
+		{
+			desc: "negative cost prices",
+			cfg: &Config{
+				Cost: CostConfig{
+					Prices: map[string]ArchitecturePrices{
+						"arm64": {GBSecond: -1},
+					},
+				},
+			},
+			expectedErr: fmt.Errorf("cost prices for arm64 must be non-negative"),
+		},
+		{
+			desc: "metric dimensions",
+			cfg: &Config{
+				Metrics: map[string]MetricConfig{
+					"faas.errors": {Dimensions: []string{"error.type", "faas.version"}, MaxSeries: 50},
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "dimensions of unknown metric",
+			cfg: &Config{
+				Metrics: map[string]MetricConfig{
+					"faas.unknown": {Dimensions: []string{"error.type"}},
+				},
+			},
+			expectedErr: fmt.Errorf("unknown metric: faas.unknown"),
+		},
+		{
+			desc: "unsupported dimension",
+			cfg: &Config{
+				Metrics: map[string]MetricConfig{
+					"faas.errors": {Dimensions: []string{"faas.invocation_id"}},
+				},
+			},
+			expectedErr: fmt.Errorf("unsupported dimension for faas.errors: faas.invocation_id"),
+		},
+		{
+			desc: "negative max series",
+			cfg: &Config{
+				Metrics: map[string]MetricConfig{
+					"faas.errors": {MaxSeries: -1},
+				},
+			},
+			expectedErr: fmt.Errorf("max_series for faas.errors must be non-negative: -1"),
+		},
+		{
+			desc: "histogram buckets",
+			cfg: &Config{
+				Metrics: map[string]MetricConfig{
+					"faas.invoke_duration": {Buckets: []float64{0.5, 1, 1.5, 2}},
+					"faas.init_duration":   {Exponential: &ExponentialHistogramConfig{MaxSize: 80}},
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "buckets on counter",
+			cfg: &Config{
+				Metrics: map[string]MetricConfig{
+					"faas.errors": {Buckets: []float64{1, 2}},
+				},
+			},
+			expectedErr: fmt.Errorf("faas.errors is not a histogram"),
+		},
+		{
+			desc: "unsorted buckets",
+			cfg: &Config{
+				Metrics: map[string]MetricConfig{
+					"faas.invoke_duration": {Buckets: []float64{1, 0.5}},
+				},
+			},
+			expectedErr: fmt.Errorf("buckets for faas.invoke_duration must be strictly increasing"),
+		},
+		{
+			desc: "buckets and exponential",
+			cfg: &Config{
+				Metrics: map[string]MetricConfig{
+					"faas.invoke_duration": {Buckets: []float64{1}, Exponential: &ExponentialHistogramConfig{}},
+				},
+			},
+			expectedErr: fmt.Errorf("buckets and exponential are mutually exclusive for faas.invoke_duration"),
+		},
+		{
+			desc: "exponential max size too small",
+			cfg: &Config{
+				Metrics: map[string]MetricConfig{
+					"faas.invoke_duration": {Exponential: &ExponentialHistogramConfig{MaxSize: 1}},
+				},
+			},
+			expectedErr: fmt.Errorf("exponential max_size for faas.invoke_duration must be at least 2: 1"),
+		},
+		{
+			desc: "exponential max scale out of range",
+			cfg: &Config{
+				Metrics: map[string]MetricConfig{
+					"faas.invoke_duration": {Exponential: &ExponentialHistogramConfig{MaxScale: func() *int { s := 21; return &s }()}},
+				},
+			},
+			expectedErr: fmt.Errorf("exponential max_scale for faas.invoke_duration must be between -10 and 20: 21"),
+		},
+		{
+			desc: "buffering",
+			cfg: &Config{
+				Buffering: BufferingConfig{MaxItems: 5000, TimeoutMS: 25},
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "buffering max items out of range",
+			cfg: &Config{
+				Buffering: BufferingConfig{MaxItems: 999},
+			},
+			expectedErr: fmt.Errorf("buffering max_items must be between 1000 and 10000: 999"),
+		},
+		{
+			desc: "buffering max bytes out of range",
+			cfg: &Config{
+				Buffering: BufferingConfig{MaxBytes: 2 * 1048576},
+			},
+			expectedErr: fmt.Errorf("buffering max_bytes must be between 262144 and 1048576: 2097152"),
+		},
+		{
+			desc: "buffering timeout out of range",
+			cfg: &Config{
+				Buffering: BufferingConfig{TimeoutMS: -1},
+			},
+			expectedErr: fmt.Errorf("buffering timeout_ms must be between 25 and 30000: -1"),
+		},
+		{
+			desc: "negative backpressure max retries",
+			cfg: &Config{
+				Backpressure: BackpressureConfig{Enabled: true, MaxRetries: -1},
+			},
+			expectedErr: fmt.Errorf("backpressure max_retries must be non-negative: -1"),
+		},
+		{
+			desc: "tcp protocol",
+			cfg: &Config{
+				Protocol: "tcp",
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "invalid protocol",
+			cfg: &Config{
+				Protocol: "udp",
+			},
+			expectedErr: fmt.Errorf("unknown protocol: udp"),
+		},
+		{
+			desc: "backpressure with tcp protocol",
+			cfg: &Config{
+				Protocol:     "tcp",
+				Backpressure: BackpressureConfig{Enabled: true},
+			},
+			expectedErr: fmt.Errorf("backpressure requires the http protocol"),
+		},
+		{
+			desc: "log sources",
+			cfg: &Config{
+				LogSources: []string{platform, extension},
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "invalid log source",
+			cfg: &Config{
+				LogSources: []string{"platform.start"},
+			},
+			expectedErr: fmt.Errorf("unknown log source: platform.start"),
+		},
+		{
+			desc: "invalid schema version",
+			cfg: &Config{
+				SchemaVersion: "2021-01-01",
+			},
+			expectedErr: fmt.Errorf("unknown schema version: 2021-01-01"),
+		},
+		{
+			desc: "emf drop logs",
+			cfg: &Config{
+				EMF: EMFConfig{Enabled: true, DropLogs: true},
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "emf drop logs without emf",
+			cfg: &Config{
+				EMF: EMFConfig{DropLogs: true},
+			},
+			expectedErr: fmt.Errorf("emf drop_logs requires emf to be enabled"),
+		},
+		{
+			desc: "structured platform log format",
+			cfg: &Config{
+				PlatformLogFormat: "structured",
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "structured platform log format without body",
+			cfg: &Config{
+				PlatformLogFormat: "structured_no_body",
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "invalid platform log format",
+			cfg: &Config{
+				PlatformLogFormat: "json",
+			},
+			expectedErr: fmt.Errorf("unknown platform log format: json"),
+		},
+		{
+			desc: "text log format",
+			cfg: &Config{
+				TextLogFormat: "python",
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "invalid text log format",
+			cfg: &Config{
+				TextLogFormat: "ruby",
+			},
+			expectedErr: fmt.Errorf("unknown text log format: ruby"),
+		},
+		{
+			desc: "multiline preset",
+			cfg: &Config{
+				Multiline: &MultilineConfig{Preset: "java", MaxLines: 100},
+			},
+			expectedErr: nil,
+		},
+		{
+			desc: "invalid multiline preset",
+			cfg: &Config{
+				Multiline: &MultilineConfig{Preset: "ruby"},
+			},
+			expectedErr: fmt.Errorf("unknown multiline preset: ruby"),
+		},
+		{
+			desc: "multiline preset with continuation pattern",
+			cfg: &Config{
+				Multiline: &MultilineConfig{Preset: "java", ContinuationPattern: `^\s`},
+			},
+			expectedErr: fmt.Errorf("multiline preset and continuation_pattern are mutually exclusive"),
+		},
+		{
+			desc: "multiline without pattern",
+			cfg: &Config{
+				Multiline: &MultilineConfig{MaxLines: 10},
+			},
+			expectedErr: fmt.Errorf("multiline requires a preset, start_pattern or continuation_pattern"),
+		},
+		{
+			desc: "invalid multiline start pattern",
+			cfg: &Config{
+				Multiline: &MultilineConfig{StartPattern: "("},
+			},
+			expectedErr: fmt.Errorf("invalid multiline start_pattern: error parsing regexp: missing closing ): `(`"),
+		},
+		{
+			desc: "negative multiline max lines",
+			cfg: &Config{
+				Multiline: &MultilineConfig{ContinuationPattern: `^\s`, MaxLines: -1},
+			},
+			expectedErr: fmt.Errorf("multiline max_lines must be non-negative: -1"),
+		},
+		{
+			desc: "negative multiline flush timeout",
+			cfg: &Config{
+				Multiline: &MultilineConfig{ContinuationPattern: `^\s`, FlushTimeoutMS: -1},
+			},
+			expectedErr: fmt.Errorf("multiline flush_timeout_ms must be non-negative: -1"),
+		},
+		{
+			desc: "invalid id generation",
+			cfg: &Config{
+				IDGeneration: "sequential",
+			},
+			expectedErr: fmt.Errorf("unknown id generation: sequential"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,292 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-lambda/collector/internal/telemetryapi"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// emfMetadataKey is the root field of the CloudWatch Embedded Metric Format (EMF) metadata.
+// See https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
+const emfMetadataKey = "_aws"
+
+// emfUnits maps the CloudWatch units to UCUM units.
+var emfUnits = map[string]string{
+	"Seconds":          "s",
+	"Microseconds":     "us",
+	"Milliseconds":     "ms",
+	"Bytes":            "By",
+	"Kilobytes":        "kBy",
+	"Megabytes":        "MBy",
+	"Gigabytes":        "GBy",
+	"Terabytes":        "TBy",
+	"Bits":             "bit",
+	"Kilobits":         "kbit",
+	"Megabits":         "Mbit",
+	"Gigabits":         "Gbit",
+	"Terabits":         "Tbit",
+	"Percent":          "%",
+	"Count":            "1",
+	"Bytes/Second":     "By/s",
+	"Kilobytes/Second": "kBy/s",
+	"Megabytes/Second": "MBy/s",
+	"Gigabytes/Second": "GBy/s",
+	"Terabytes/Second": "TBy/s",
+	"Bits/Second":      "bit/s",
+	"Kilobits/Second":  "kbit/s",
+	"Megabits/Second":  "Mbit/s",
+	"Gigabits/Second":  "Gbit/s",
+	"Terabits/Second":  "Tbit/s",
+	"Count/Second":     "1/s",
+	"None":             "",
+}
+
+type emfMetadata struct {
+	Timestamp         float64        `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+type emfDirective struct {
+	Namespace  string          `json:"Namespace"`
+	Dimensions [][]string      `json:"Dimensions"`
+	Metrics    []emfDefinition `json:"Metrics"`
+}
+
+type emfDefinition struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+// parseEMF returns the EMF document carried by a function log record: the record itself in the JSON log
+// format, or the line in the text log format or in the message of the JSON log format.
+func parseEMF(record any) (map[string]any, bool) {
+	switch rec := record.(type) {
+	case string:
+		if !strings.HasPrefix(strings.TrimSpace(rec), "{") || !strings.Contains(rec, emfMetadataKey) {
+			return nil, false
+		}
+		var doc map[string]any
+		if err := json.Unmarshal([]byte(rec), &doc); err != nil {
+			return nil, false
+		}
+		return doc, isEMF(doc)
+	case map[string]any:
+		if isEMF(rec) {
+			return rec, true
+		}
+		if message, ok := rec["message"].(string); ok {
+			return parseEMF(message)
+		}
+	}
+	return nil, false
+}
+
+func isEMF(doc map[string]any) bool {
+	metadata, ok := doc[emfMetadataKey].(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = metadata["CloudWatchMetrics"].([]any)
+	return ok
+}
+
+// extractEMFMetrics converts the EMF documents logged by the function into gauges and summaries, one series
+// per dimension set, buffered until the next metrics export. Events metrics were extracted from are marked
+// so that their log records can be dropped.
+func (r *telemetryAPIReceiver) extractEMFMetrics(slice []event) {
+	for i, el := range slice {
+		if el.Type != string(telemetryapi.Function) {
+			continue
+		}
+		doc, ok := parseEMF(el.Record)
+		if !ok {
+			continue
+		}
+		var metadata emfMetadata
+		raw, err := json.Marshal(doc[emfMetadataKey])
+		if err == nil {
+			err = json.Unmarshal(raw, &metadata)
+		}
+		if err != nil {
+			r.logger.Debug("invalid EMF metadata", zap.Error(err))
+			continue
+		}
+
+		ts := pcommon.NewTimestampFromTime(time.UnixMilli(int64(metadata.Timestamp)))
+		if metadata.Timestamp == 0 {
+			if t, err := time.Parse(time.RFC3339, el.Time); err == nil {
+				ts = pcommon.NewTimestampFromTime(t)
+			}
+		}
+		for _, directive := range metadata.CloudWatchMetrics {
+			r.appendEMFDirective(doc, directive, ts)
+		}
+		slice[i].emf = true
+	}
+}
+
+func (r *telemetryAPIReceiver) appendEMFDirective(doc map[string]any, directive emfDirective, ts pcommon.Timestamp) {
+	dimensionSets := emfDimensionSets(doc, directive.Dimensions)
+	scope := r.emfScope(directive.Namespace)
+	for _, definition := range directive.Metrics {
+		values := emfValues(doc[definition.Name])
+		if len(values) == 0 {
+			continue
+		}
+		m := emfMetric(scope, definition, len(values))
+		for _, attrs := range dimensionSets {
+			appendEMFValues(m, values, attrs, ts)
+		}
+	}
+}
+
+// emfDimensionSets returns the attributes of each dimension set of a directive. CloudWatch aggregates the
+// metrics of a directive for each of its dimension sets, so each set becomes a series of its own. Sets
+// referencing a dimension the document lacks are skipped, as CloudWatch rejects them.
+func emfDimensionSets(doc map[string]any, dimensions [][]string) []pcommon.Map {
+	if len(dimensions) == 0 {
+		return []pcommon.Map{pcommon.NewMap()}
+	}
+	sets := make([]pcommon.Map, 0, len(dimensions))
+	for _, set := range dimensions {
+		attrs := pcommon.NewMap()
+		complete := true
+		for _, key := range set {
+			value, ok := doc[key]
+			if !ok {
+				complete = false
+				break
+			}
+			if err := attrs.PutEmpty(key).FromRaw(value); err != nil {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			sets = append(sets, attrs)
+		}
+	}
+	return sets
+}
+
+// emfScope returns the buffered scope of the EMF namespace, which is used as the instrumentation scope name.
+func (r *telemetryAPIReceiver) emfScope(namespace string) pmetric.ScopeMetrics {
+	for i := 0; i < r.emfMetrics.Len(); i++ {
+		if scope := r.emfMetrics.At(i); scope.Scope().Name() == namespace {
+			return scope
+		}
+	}
+	scope := r.emfMetrics.AppendEmpty()
+	scope.Scope().SetName(namespace)
+	return scope
+}
+
+// emfMetric returns the buffered metric of the definition, creating it as a gauge for single values and
+// as a summary for arrays of values.
+func emfMetric(scope pmetric.ScopeMetrics, definition emfDefinition, numValues int) pmetric.Metric {
+	for i := 0; i < scope.Metrics().Len(); i++ {
+		if m := scope.Metrics().At(i); m.Name() == definition.Name {
+			return m
+		}
+	}
+	m := scope.Metrics().AppendEmpty()
+	m.SetName(definition.Name)
+	if unit, ok := emfUnits[definition.Unit]; ok {
+		m.SetUnit(unit)
+	} else {
+		m.SetUnit(definition.Unit)
+	}
+	if numValues == 1 {
+		m.SetEmptyGauge()
+	} else {
+		m.SetEmptySummary()
+	}
+	return m
+}
+
+// appendEMFValues appends the values logged for a metric to its series. A single value is a gauge data
+// point. An array holds several observations that would collapse into one value as gauge data points
+// sharing a timestamp, so it is aggregated into a summary data point instead. A gauge receiving an
+// array is turned into a summary, keeping a single type per metric.
+func appendEMFValues(m pmetric.Metric, values []float64, attrs pcommon.Map, ts pcommon.Timestamp) {
+	if m.Type() == pmetric.MetricTypeGauge && len(values) == 1 {
+		dp := m.Gauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(ts)
+		dp.SetDoubleValue(values[0])
+		attrs.CopyTo(dp.Attributes())
+		return
+	}
+	if m.Type() == pmetric.MetricTypeGauge {
+		gaugeToSummary(m)
+	}
+	appendSummaryDataPoint(m.Summary().DataPoints(), values, attrs, ts)
+}
+
+// appendSummaryDataPoint appends the count and sum of the values, with their minimum and maximum as the
+// 0 and 1 quantiles.
+func appendSummaryDataPoint(dps pmetric.SummaryDataPointSlice, values []float64, attrs pcommon.Map, ts pcommon.Timestamp) {
+	dp := dps.AppendEmpty()
+	dp.SetTimestamp(ts)
+	attrs.CopyTo(dp.Attributes())
+	minValue, maxValue := values[0], values[0]
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+		minValue = min(minValue, value)
+		maxValue = max(maxValue, value)
+	}
+	dp.SetCount(uint64(len(values)))
+	dp.SetSum(sum)
+	q := dp.QuantileValues().AppendEmpty()
+	q.SetQuantile(0)
+	q.SetValue(minValue)
+	q = dp.QuantileValues().AppendEmpty()
+	q.SetQuantile(1)
+	q.SetValue(maxValue)
+}
+
+func gaugeToSummary(m pmetric.Metric) {
+	gauge := pmetric.NewNumberDataPointSlice()
+	m.Gauge().DataPoints().MoveAndAppendTo(gauge)
+	summary := m.SetEmptySummary()
+	for i := 0; i < gauge.Len(); i++ {
+		dp := gauge.At(i)
+		appendSummaryDataPoint(summary.DataPoints(), []float64{dp.DoubleValue()}, dp.Attributes(), dp.Timestamp())
+	}
+}
+
+// emfValues returns the values of a metric, logged as a single number or as an array of numbers.
+func emfValues(raw any) []float64 {
+	switch v := raw.(type) {
+	case float64:
+		return []float64{v}
+	case []any:
+		values := make([]float64, 0, len(v))
+		for _, value := range v {
+			if f, ok := value.(float64); ok {
+				values = append(values, f)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+const testEMFLine = `{"_aws":{"Timestamp":1704207845123,"CloudWatchMetrics":[{"Namespace":"orders","Dimensions":[["service"],["service","operation"]],"Metrics":[{"Name":"latency","Unit":"Milliseconds"},{"Name":"processed","Unit":"Count"},{"Name":"missing"}]}]},"service":"checkout","operation":"pay","latency":[12.5,20],"processed":3}`
+
+func TestParseEMF(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		record any
+		ok     bool
+	}{
+		{
+			desc:   "text line",
+			record: testEMFLine,
+			ok:     true,
+		},
+		{
+			desc:   "json record",
+			record: map[string]any{"_aws": map[string]any{"CloudWatchMetrics": []any{}}, "latency": 1.0},
+			ok:     true,
+		},
+		{
+			desc:   "json message",
+			record: map[string]any{"level": "INFO", "message": testEMFLine},
+			ok:     true,
+		},
+		{
+			desc:   "json without metadata",
+			record: `{"_aws_like":true}`,
+			ok:     false,
+		},
+		{
+			desc:   "invalid json",
+			record: `{"_aws":`,
+			ok:     false,
+		},
+		{
+			desc:   "plain text",
+			record: "hello _aws",
+			ok:     false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, ok := parseEMF(tc.record)
+			require.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestEMFMetrics(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		cfg          EMFConfig
+		expectedLogs int
+	}{
+		{
+			desc:         "pass logs through",
+			cfg:          EMFConfig{Enabled: true},
+			expectedLogs: 2,
+		},
+		{
+			desc:         "drop logs",
+			cfg:          EMFConfig{Enabled: true, DropLogs: true},
+			expectedLogs: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := newTelemetryAPIReceiver(&Config{EMF: tc.cfg}, receivertest.NewNopSettings(Type))
+			require.NoError(t, err)
+			metricsSink := &consumertest.MetricsSink{}
+			logsSink := &consumertest.LogsSink{}
+			r.registerMetricsConsumer(metricsSink)
+			r.registerLogsConsumer(logsSink)
+
+			body := `[
+				{"time":"2024-01-02T15:04:05.000Z","type":"function","record":` + strconv.Quote(testEMFLine) + `},
+				{"time":"2024-01-02T15:04:05.001Z","type":"function","record":"hello"}
+			]`
+			r.httpHandler(httptest.NewRecorder(), httptest.NewRequest("POST", "http://localhost/", strings.NewReader(body)))
+
+			require.Equal(t, tc.expectedLogs, logsSink.LogRecordCount())
+			require.Len(t, metricsSink.AllMetrics(), 1)
+
+			scopes := metricsSink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics()
+			var emf pmetric.ScopeMetrics
+			for i := 0; i < scopes.Len(); i++ {
+				if scopes.At(i).Scope().Name() == "orders" {
+					emf = scopes.At(i)
+				}
+			}
+			require.Equal(t, 2, emf.Metrics().Len())
+
+			// The latency array is aggregated, for each dimension set.
+			latency := emf.Metrics().At(0)
+			require.Equal(t, "latency", latency.Name())
+			require.Equal(t, "ms", latency.Unit())
+			require.Equal(t, 2, latency.Summary().DataPoints().Len())
+			dp := latency.Summary().DataPoints().At(0)
+			require.Equal(t, time.UnixMilli(1704207845123).UTC(), dp.Timestamp().AsTime())
+			require.Equal(t, map[string]any{"service": "checkout"}, dp.Attributes().AsRaw())
+			require.Equal(t, uint64(2), dp.Count())
+			require.Equal(t, 32.5, dp.Sum())
+			require.Equal(t, 12.5, dp.QuantileValues().At(0).Value())
+			require.Equal(t, 20.0, dp.QuantileValues().At(1).Value())
+			require.Equal(t, map[string]any{"service": "checkout", "operation": "pay"}, latency.Summary().DataPoints().At(1).Attributes().AsRaw())
+
+			processed := emf.Metrics().At(1)
+			require.Equal(t, "processed", processed.Name())
+			require.Equal(t, "1", processed.Unit())
+			require.Equal(t, 2, processed.Gauge().DataPoints().Len())
+			require.Equal(t, 3.0, processed.Gauge().DataPoints().At(0).DoubleValue())
+			require.Equal(t, map[string]any{"service": "checkout"}, processed.Gauge().DataPoints().At(0).Attributes().AsRaw())
+			require.Equal(t, map[string]any{"service": "checkout", "operation": "pay"}, processed.Gauge().DataPoints().At(1).Attributes().AsRaw())
+
+			// The buffer is emptied by the export.
+			require.Equal(t, 0, r.emfMetrics.Len())
+		})
+	}
+}
+
+func TestEMFDimensionSets(t *testing.T) {
+	doc := map[string]any{"service": "checkout", "operation": "pay"}
+	testCases := []struct {
+		desc       string
+		dimensions [][]string
+		expected   []map[string]any
+	}{
+		{
+			desc:     "no dimensions",
+			expected: []map[string]any{{}},
+		},
+		{
+			desc:       "empty dimension set",
+			dimensions: [][]string{{}},
+			expected:   []map[string]any{{}},
+		},
+		{
+			desc:       "one series per set",
+			dimensions: [][]string{{"service"}, {"service", "operation"}},
+			expected:   []map[string]any{{"service": "checkout"}, {"service": "checkout", "operation": "pay"}},
+		},
+		{
+			desc:       "set with missing dimension",
+			dimensions: [][]string{{"service", "region"}, {"operation"}},
+			expected:   []map[string]any{{"operation": "pay"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sets := emfDimensionSets(doc, tc.dimensions)
+			actual := make([]map[string]any, 0, len(sets))
+			for _, set := range sets {
+				actual = append(actual, set.AsRaw())
+			}
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestEMFGaugeToSummary(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{EMF: EMFConfig{Enabled: true}}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	r.extractEMFMetrics([]event{
+		{Type: "function", Record: `{"_aws":{"Timestamp":1704207845000,"CloudWatchMetrics":[{"Namespace":"orders","Metrics":[{"Name":"latency"}]}]},"latency":10}`},
+		{Type: "function", Record: `{"_aws":{"Timestamp":1704207846000,"CloudWatchMetrics":[{"Namespace":"orders","Metrics":[{"Name":"latency"}]}]},"latency":[5,15,25]}`},
+		{Type: "function", Record: `{"_aws":{"Timestamp":1704207847000,"CloudWatchMetrics":[{"Namespace":"orders","Metrics":[{"Name":"latency"}]}]},"latency":7}`},
+	})
+
+	require.Equal(t, 1, r.emfMetrics.Len())
+	latency := r.emfMetrics.At(0).Metrics().At(0)
+	require.Equal(t, pmetric.MetricTypeSummary, latency.Type())
+	dps := latency.Summary().DataPoints()
+	require.Equal(t, 3, dps.Len())
+	expected := []struct {
+		count         uint64
+		sum, min, max float64
+	}{
+		{1, 10, 10, 10},
+		{3, 45, 5, 25},
+		{1, 7, 7, 7},
+	}
+	for i, e := range expected {
+		dp := dps.At(i)
+		require.Equal(t, e.count, dp.Count(), "data point %d", i)
+		require.Equal(t, e.sum, dp.Sum(), "data point %d", i)
+		require.Equal(t, e.min, dp.QuantileValues().At(0).Value(), "data point %d", i)
+		require.Equal(t, e.max, dp.QuantileValues().At(1).Value(), "data point %d", i)
+	}
+	require.Equal(t, time.UnixMilli(1704207845000).UTC(), dps.At(0).Timestamp().AsTime())
+}
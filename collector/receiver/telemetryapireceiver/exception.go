@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-lambda/collector/internal/telemetryapi"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+const (
+	// Fields of the errors logged by the runtimes in the JSON log format, e.g.
+	// {"errorType":"TypeError","errorMessage":"x is undefined","stackTrace":["TypeError: x is undefined","    at ..."]}
+	logFieldErrorType    = "errorType"
+	logFieldErrorMessage = "errorMessage"
+	logFieldStackTrace   = "stackTrace"
+
+	exceptionEventName = "exception"
+)
+
+// logException is an error logged by the function in the JSON log format.
+type logException struct {
+	time       time.Time
+	typ        string
+	message    string
+	stacktrace string
+}
+
+// parseLogException returns the error described by a JSON function log record. The error fields are either
+// at the top level of the record or nested in its `message` field, depending on the runtime.
+func parseLogException(record map[string]any) (logException, bool) {
+	fields := record
+	if message, ok := record["message"].(map[string]any); ok {
+		fields = message
+	}
+	typ, _ := fields[logFieldErrorType].(string)
+	message, _ := fields[logFieldErrorMessage].(string)
+	if typ == "" && message == "" {
+		return logException{}, false
+	}
+	return logException{typ: typ, message: message, stacktrace: formatStackTrace(fields[logFieldStackTrace])}, true
+}
+
+// formatStackTrace joins the frames of a stack trace logged as an array, one frame per line.
+func formatStackTrace(stackTrace any) string {
+	switch st := stackTrace.(type) {
+	case string:
+		return st
+	case []any:
+		frames := make([]string, 0, len(st))
+		for _, frame := range st {
+			if s, ok := frame.(string); ok {
+				frames = append(frames, strings.TrimSuffix(s, "\n"))
+			}
+		}
+		return strings.Join(frames, "\n")
+	}
+	return ""
+}
+
+func putExceptionAttributes(attrs pcommon.Map, exception logException) {
+	if exception.typ != "" {
+		attrs.PutStr(string(semconv.ExceptionTypeKey), exception.typ)
+	}
+	if exception.message != "" {
+		attrs.PutStr(string(semconv.ExceptionMessageKey), exception.message)
+	}
+	if exception.stacktrace != "" {
+		attrs.PutStr(string(semconv.ExceptionStacktraceKey), exception.stacktrace)
+	}
+}
+
+// setLogException replaces the error fields copied from the record with the exception attributes and raises
+// the severity to ERROR. Records without a text message get the error message as body.
+func setLogException(logRecord plog.LogRecord, exception logException) {
+	for _, key := range []string{logFieldErrorType, logFieldErrorMessage, logFieldStackTrace} {
+		logRecord.Attributes().Remove(key)
+	}
+	putExceptionAttributes(logRecord.Attributes(), exception)
+	logRecord.SetSeverityNumber(plog.SeverityNumberError)
+	logRecord.SetSeverityText(plog.SeverityNumberError.String())
+	if logRecord.Body().Type() == pcommon.ValueTypeEmpty {
+		logRecord.Body().SetStr(exception.message)
+	}
+}
+
+// recordInvocationException keeps the error logged by the function for an exception event on its invocation span.
+func (r *telemetryAPIReceiver) recordInvocationException(el event, record map[string]any) {
+	if el.Type != string(telemetryapi.Function) {
+		return
+	}
+	exception, ok := parseLogException(record)
+	if !ok {
+		return
+	}
+	requestID := r.getRecordRequestId(record)
+	if requestID == "" {
+		requestID = r.inFlightRequestID()
+	}
+	inv, ok := r.invocations[requestID]
+	if !ok {
+		return
+	}
+	exception.time, _ = time.Parse(time.RFC3339, el.Time)
+	inv.exceptions = append(inv.exceptions, exception)
+}
+
+func appendExceptionEvents(span ptrace.Span, exceptions []logException) {
+	for _, exception := range exceptions {
+		ev := span.Events().AppendEmpty()
+		ev.SetName(exceptionEventName)
+		if !exception.time.IsZero() {
+			ev.SetTimestamp(pcommon.NewTimestampFromTime(exception.time))
+		}
+		putExceptionAttributes(ev.Attributes(), exception)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+func TestParseLogException(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		record   map[string]any
+		expected logException
+		ok       bool
+	}{
+		{
+			desc: "top level fields",
+			record: map[string]any{
+				"errorType":    "ValueError",
+				"errorMessage": "bad input",
+				"stackTrace":   []any{"  File \"/var/task/app.py\", line 3, in handler\n", "    raise ValueError(\"bad input\")\n"},
+			},
+			expected: logException{typ: "ValueError", message: "bad input", stacktrace: "  File \"/var/task/app.py\", line 3, in handler\n    raise ValueError(\"bad input\")"},
+			ok:       true,
+		},
+		{
+			desc: "nested in message",
+			record: map[string]any{
+				"message": map[string]any{
+					"errorType":    "TypeError",
+					"errorMessage": "x is undefined",
+					"stackTrace":   []any{"TypeError: x is undefined", "    at handler (/var/task/index.js:3:9)"},
+				},
+			},
+			expected: logException{typ: "TypeError", message: "x is undefined", stacktrace: "TypeError: x is undefined\n    at handler (/var/task/index.js:3:9)"},
+			ok:       true,
+		},
+		{
+			desc:     "string stack trace",
+			record:   map[string]any{"errorType": "java.lang.RuntimeException", "stackTrace": "at Handler.handleRequest(Handler.java:42)"},
+			expected: logException{typ: "java.lang.RuntimeException", stacktrace: "at Handler.handleRequest(Handler.java:42)"},
+			ok:       true,
+		},
+		{
+			desc:   "no error",
+			record: map[string]any{"message": "hello", "level": "INFO"},
+			ok:     false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			exception, ok := parseLogException(tc.record)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.expected, exception)
+		})
+	}
+}
+
+func TestCreateLogsException(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	logs, err := r.createLogs([]event{
+		{
+			Time: "2024-01-02T15:04:05.000Z",
+			Type: "function",
+			Record: map[string]any{
+				"timestamp":    "2024-01-02T15:04:05.000Z",
+				"level":        "INFO",
+				"message":      "Invoke Error",
+				"requestId":    "test-id",
+				"errorType":    "ValueError",
+				"errorMessage": "bad input",
+				"stackTrace":   []any{"line 1\n", "line 2\n"},
+			},
+		},
+		{
+			Time: "2024-01-02T15:04:05.001Z",
+			Type: "function",
+			Record: map[string]any{
+				"timestamp": "2024-01-02T15:04:05.001Z",
+				"level":     "ERROR",
+				"requestId": "test-id",
+				"message":   map[string]any{"errorType": "TypeError", "errorMessage": "x is undefined"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+
+	first := records.At(0)
+	require.Equal(t, "Invoke Error", first.Body().Str())
+	require.Equal(t, plog.SeverityNumberError, first.SeverityNumber())
+	require.Equal(t, map[string]any{
+		"type":                                 "function",
+		string(semconv.FaaSInvocationIDKey):    "test-id",
+		string(semconv.ExceptionTypeKey):       "ValueError",
+		string(semconv.ExceptionMessageKey):    "bad input",
+		string(semconv.ExceptionStacktraceKey): "line 1\nline 2",
+	}, first.Attributes().AsRaw())
+
+	second := records.At(1)
+	require.Equal(t, "x is undefined", second.Body().Str())
+	require.Equal(t, plog.SeverityNumberError, second.SeverityNumber())
+	exceptionType, _ := second.Attributes().Get(string(semconv.ExceptionTypeKey))
+	require.Equal(t, "TypeError", exceptionType.Str())
+}
+
+func TestInvocationSpanExceptionEvents(t *testing.T) {
+	const requestID = "34472c47-5ff0-4df5-a9ad-03776afa5473"
+
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	start := map[string]any{"requestId": requestID}
+	r.trackInFlight("platform.start", start)
+	r.startInvocation("2022-10-12T00:03:50.000Z", start)
+	r.recordInvocationException(event{Time: "2022-10-12T00:03:50.050Z", Type: "function"}, map[string]any{
+		"message": map[string]any{"errorType": "TypeError", "errorMessage": "x is undefined", "stackTrace": []any{"at handler"}},
+	})
+	r.recordInvocationException(event{Time: "2022-10-12T00:03:50.060Z", Type: "function"}, map[string]any{"message": "no error"})
+	r.finishInvocation("2022-10-12T00:03:50.100Z", map[string]any{"requestId": requestID, "status": "error", "errorType": "TypeError"})
+	td, ok := r.reportInvocation(map[string]any{"requestId": requestID})
+	require.True(t, ok)
+
+	span := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	require.Equal(t, 1, span.Events().Len())
+	ev := span.Events().At(0)
+	require.Equal(t, "exception", ev.Name())
+	require.Equal(t, time.Date(2022, 10, 12, 0, 3, 50, 50000000, time.UTC), ev.Timestamp().AsTime())
+	require.Equal(t, map[string]any{
+		string(semconv.ExceptionTypeKey):       "TypeError",
+		string(semconv.ExceptionMessageKey):    "x is undefined",
+		string(semconv.ExceptionStacktraceKey): "at handler",
+	}, ev.Attributes().AsRaw())
+}
@@ -0,0 +1,161 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver
+
+import (
+	"math"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const (
+	// DefaultExponentialHistogramMaxSize is the default maximum number of buckets of each range of an exponential histogram.
+	DefaultExponentialHistogramMaxSize = 160
+	// DefaultExponentialHistogramMaxScale is the default scale exponential histograms start at before downscaling.
+	DefaultExponentialHistogramMaxScale = 20
+	// MinExponentialHistogramScale and MaxExponentialHistogramScale bound the scales supported by the data model.
+	MinExponentialHistogramScale = -10
+	MaxExponentialHistogramScale = 20
+)
+
+// exponentialHistogram aggregates values into base-2 exponential buckets. It starts at the maximum scale and
+// downscales whenever recording a value would need more than the maximum number of buckets.
+// See https://opentelemetry.io/docs/specs/otel/metrics/data-model/#exponentialhistogram
+type exponentialHistogram struct {
+	scale     int32
+	zeroCount uint64
+	positive  exponentialBuckets
+	negative  exponentialBuckets
+	min       float64
+	max       float64
+}
+
+// exponentialBuckets holds the counts of consecutive buckets, the first of which has index offset.
+type exponentialBuckets struct {
+	offset int32
+	counts []uint64
+}
+
+func newExponentialHistogram(maxScale int32) *exponentialHistogram {
+	return &exponentialHistogram{
+		scale: maxScale,
+		min:   math.Inf(1),
+		max:   math.Inf(-1),
+	}
+}
+
+func (e *exponentialHistogram) record(value float64, maxSize int) {
+	e.min = math.Min(e.min, value)
+	e.max = math.Max(e.max, value)
+	if value == 0 {
+		e.zeroCount++
+		return
+	}
+
+	buckets := &e.positive
+	if value < 0 {
+		buckets = &e.negative
+		value = -value
+	}
+
+	index := mapToIndex(value, e.scale)
+	if change := buckets.scaleChange(index, maxSize); change > 0 {
+		e.downscale(change)
+		index = mapToIndex(value, e.scale)
+	}
+	buckets.increment(index)
+}
+
+// downscale lowers the scale by change, merging 2^change adjacent buckets into one.
+func (e *exponentialHistogram) downscale(change int32) {
+	e.positive.downscale(change)
+	e.negative.downscale(change)
+	e.scale -= change
+}
+
+func (e *exponentialHistogram) copyTo(dp pmetric.ExponentialHistogramDataPoint) {
+	dp.SetScale(e.scale)
+	dp.SetZeroCount(e.zeroCount)
+	dp.Positive().SetOffset(e.positive.offset)
+	dp.Positive().BucketCounts().FromRaw(e.positive.counts)
+	dp.Negative().SetOffset(e.negative.offset)
+	dp.Negative().BucketCounts().FromRaw(e.negative.counts)
+	dp.SetMin(e.min)
+	dp.SetMax(e.max)
+}
+
+// scaleChange returns by how much the scale must be lowered for the buckets to also cover index within maxSize buckets.
+func (b *exponentialBuckets) scaleChange(index int32, maxSize int) int32 {
+	if len(b.counts) == 0 {
+		return 0
+	}
+	low := min(b.offset, index)
+	high := max(b.offset+int32(len(b.counts))-1, index)
+
+	var change int32
+	for int(high-low) >= maxSize {
+		low >>= 1
+		high >>= 1
+		change++
+	}
+	return change
+}
+
+func (b *exponentialBuckets) increment(index int32) {
+	switch {
+	case len(b.counts) == 0:
+		b.offset = index
+		b.counts = []uint64{0}
+	case index < b.offset:
+		counts := make([]uint64, int(b.offset-index)+len(b.counts))
+		copy(counts[b.offset-index:], b.counts)
+		b.counts = counts
+		b.offset = index
+	case int(index-b.offset) >= len(b.counts):
+		b.counts = append(b.counts, make([]uint64, int(index-b.offset)-len(b.counts)+1)...)
+	}
+	b.counts[index-b.offset]++
+}
+
+func (b *exponentialBuckets) downscale(change int32) {
+	if len(b.counts) == 0 || change == 0 {
+		return
+	}
+	offset := b.offset >> change
+	counts := make([]uint64, int((b.offset+int32(len(b.counts))-1)>>change-offset)+1)
+	for i, c := range b.counts {
+		counts[(b.offset+int32(i))>>change-offset] += c
+	}
+	b.offset = offset
+	b.counts = counts
+}
+
+// mapToIndex returns the index of the bucket holding the positive value at the given scale.
+// Bucket i holds the values in (base^i, base^(i+1)] where base = 2^(2^-scale).
+func mapToIndex(value float64, scale int32) int32 {
+	frac, exp := math.Frexp(value)
+	if scale <= 0 {
+		// Exact powers of two belong to the bucket below.
+		if frac == 0.5 {
+			exp--
+		}
+		return int32(exp-1) >> -scale
+	}
+	if frac == 0.5 {
+		return int32(exp-1)<<scale - 1
+	}
+	scaleFactor := math.Ldexp(math.Log2E, int(scale))
+	return int32(math.Ceil(math.Log(value)*scaleFactor)) - 1
+}
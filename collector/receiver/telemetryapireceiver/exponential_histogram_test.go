@@ -0,0 +1,122 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestMapToIndex(t *testing.T) {
+	testCases := []struct {
+		value    float64
+		scale    int32
+		expected int32
+	}{
+		{value: 1, scale: 0, expected: -1},
+		{value: 1.5, scale: 0, expected: 0},
+		{value: 2, scale: 0, expected: 0},
+		{value: 3, scale: 0, expected: 1},
+		{value: 0.25, scale: 0, expected: -3},
+		{value: 4, scale: -1, expected: 0},
+		{value: 5, scale: -1, expected: 1},
+		{value: 2, scale: 1, expected: 1},
+		{value: 1.5, scale: 1, expected: 1},
+		{value: 1.2, scale: 1, expected: 0},
+		{value: 1024, scale: 3, expected: 79},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, mapToIndex(tc.value, tc.scale), "value %v at scale %d", tc.value, tc.scale)
+	}
+}
+
+func TestExponentialHistogram_Record(t *testing.T) {
+	t.Run("zero and negative values", func(t *testing.T) {
+		e := newExponentialHistogram(0)
+		e.record(0, 160)
+		e.record(-3, 160)
+		e.record(3, 160)
+
+		assert.Equal(t, uint64(1), e.zeroCount)
+		assert.Equal(t, exponentialBuckets{offset: 1, counts: []uint64{1}}, e.negative)
+		assert.Equal(t, exponentialBuckets{offset: 1, counts: []uint64{1}}, e.positive)
+		assert.Equal(t, -3.0, e.min)
+		assert.Equal(t, 3.0, e.max)
+	})
+
+	t.Run("downscales to fit max size", func(t *testing.T) {
+		e := newExponentialHistogram(0)
+		e.record(1.5, 4) // index 0
+		e.record(3, 4)   // index 1
+		e.record(20, 4)  // index 4 needs 5 buckets, downscale to scale -1
+
+		assert.Equal(t, int32(-1), e.scale)
+		assert.Equal(t, exponentialBuckets{offset: 0, counts: []uint64{2, 0, 1}}, e.positive)
+	})
+
+	t.Run("extends buckets below the offset", func(t *testing.T) {
+		e := newExponentialHistogram(0)
+		e.record(10, 160)
+		e.record(0.3, 160)
+
+		assert.Equal(t, int32(-2), e.positive.offset)
+		assert.Equal(t, []uint64{1, 0, 0, 0, 0, 1}, e.positive.counts)
+	})
+}
+
+func TestExponentialHistogramMetricBuilder_AppendDataPoints(t *testing.T) {
+	startTime := pcommon.NewTimestampFromTime(time.Now().Add(-time.Hour))
+	builder := NewExponentialHistogramMetricBuilder("test.histogram", "Test exponential histogram", "s", 20, 3, startTime, pmetric.AggregationTemporalityDelta)
+
+	for _, v := range []float64{0.12, 0.25, 1.1, 1.9, 2.4} {
+		builder.Record(v)
+	}
+
+	scopeMetrics := pmetric.NewScopeMetrics()
+	timestamp := pcommon.NewTimestampFromTime(time.Now())
+	builder.AppendDataPoints(scopeMetrics, timestamp)
+
+	require.Equal(t, 1, scopeMetrics.Metrics().Len())
+	metric := scopeMetrics.Metrics().At(0)
+	require.Equal(t, pmetric.MetricTypeExponentialHistogram, metric.Type())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, metric.ExponentialHistogram().AggregationTemporality())
+
+	dp := metric.ExponentialHistogram().DataPoints().At(0)
+	assert.Equal(t, startTime, dp.StartTimestamp())
+	assert.Equal(t, timestamp, dp.Timestamp())
+	assert.Equal(t, uint64(5), dp.Count())
+	assert.InDelta(t, 5.77, dp.Sum(), 1e-9)
+	assert.Equal(t, 0.12, dp.Min())
+	assert.Equal(t, 2.4, dp.Max())
+
+	// The values span from bucket -25 to 10 at scale 3, which needs downscaling once to fit 20 buckets.
+	assert.Equal(t, int32(2), dp.Scale())
+	var total uint64
+	for _, c := range dp.Positive().BucketCounts().AsRaw() {
+		total += c
+	}
+	assert.Equal(t, uint64(5), total)
+	assert.LessOrEqual(t, dp.Positive().BucketCounts().Len(), 20)
+
+	// Delta temporality resets the data points after export.
+	next := pmetric.NewScopeMetrics()
+	builder.AppendDataPoints(next, timestamp)
+	assert.Equal(t, 0, next.Metrics().Len())
+}
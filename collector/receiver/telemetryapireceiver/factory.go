@@ -25,13 +25,37 @@ import (
 )
 
 const (
-	typeStr               = "telemetryapi"
-	stability             = component.StabilityLevelDevelopment
-	defaultPort           = 0
-	defaultExportInterval = 60000
-	platform              = "platform"
-	function              = "function"
-	extension             = "extension"
+	typeStr                       = "telemetryapi"
+	stability                     = component.StabilityLevelDevelopment
+	defaultPort                   = 0
+	defaultExportInterval         = 60000
+	defaultBackpressureMaxRetries = 3
+	platform                      = "platform"
+	function                      = "function"
+	extension                     = "extension"
+
+	protocolHTTP = "http"
+	protocolTCP  = "tcp"
+
+	idGenerationRandom        = "random"
+	idGenerationDeterministic = "deterministic"
+
+	architectureX86 = "x86_64"
+	architectureArm = "arm64"
+
+	textLogFormatAuto   = "auto"
+	textLogFormatNone   = "none"
+	textLogFormatNodejs = "nodejs"
+	textLogFormatPython = "python"
+	textLogFormatJava   = "java"
+
+	platformLogFormatText             = "text"
+	platformLogFormatStructured       = "structured"
+	platformLogFormatStructuredNoBody = "structured_no_body"
+
+	multilinePresetJava   = "java"
+	multilinePresetPython = "python"
+	multilinePresetNodejs = "nodejs"
 )
 
 var (
@@ -49,6 +73,7 @@ func NewFactory(extensionID string) receiver.Factory {
 				Port:           defaultPort,
 				Types:          []string{platform, function, extension},
 				ExportInterval: defaultExportInterval,
+				Backpressure:   BackpressureConfig{MaxRetries: defaultBackpressureMaxRetries},
 			}
 		},
 		receiver.WithTraces(createTracesReceiver, stability),
@@ -46,6 +46,7 @@ func TestNewFactory(t *testing.T) {
 					Port:           defaultPort,
 					Types:          []string{platform, function, extension},
 					ExportInterval: defaultExportInterval,
+					Backpressure:   BackpressureConfig{MaxRetries: defaultBackpressureMaxRetries},
 				}
 
 				require.Equal(t, expectedCfg, factory.CreateDefaultConfig())
@@ -0,0 +1,260 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-lambda/collector/internal/telemetryapi"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+const (
+	attributeBilledDurationMs = "aws.lambda.billed_duration_ms"
+	attributeMaxMemoryUsedMB  = "aws.lambda.max_memory_used_mb"
+	attributeMemorySizeMB     = "aws.lambda.memory_size_mb"
+	attributeInitDurationMs   = "aws.lambda.init_duration_ms"
+)
+
+// invocation holds the state collected for a single request ID between platform.start and platform.report.
+type invocation struct {
+	requestID string
+	start     time.Time
+	end       time.Time
+	status    string
+	errorType string
+	tracing   *traceContext
+	spans     []any
+
+	exceptions []logException // errors logged by the function, reported as span events
+}
+
+// startInvocation records the start of the invocation described by a platform.start event.
+func (r *telemetryAPIReceiver) startInvocation(eventTime string, record map[string]any) {
+	requestID := r.getRecordRequestId(record)
+	if requestID == "" {
+		return
+	}
+	startTime, err := time.Parse(time.RFC3339, eventTime)
+	if err != nil {
+		return
+	}
+	inv := r.getOrCreateInvocation(requestID)
+	inv.start = startTime
+	inv.setTracing(record)
+}
+
+// finishInvocation records the end of the invocation described by a platform.runtimeDone event.
+func (r *telemetryAPIReceiver) finishInvocation(eventTime string, record map[string]any) {
+	requestID := r.getRecordRequestId(record)
+	if requestID == "" {
+		return
+	}
+	endTime, err := time.Parse(time.RFC3339, eventTime)
+	if err != nil {
+		return
+	}
+	inv := r.getOrCreateInvocation(requestID)
+	inv.end = endTime
+	inv.status, _ = record["status"].(string)
+	inv.errorType, _ = record["errorType"].(string)
+	inv.spans, _ = record["spans"].([]any)
+	inv.setTracing(record)
+}
+
+// reportInvocation completes the invocation described by a platform.report event and returns its span.
+// The invocation is forgotten once reported, whether a span could be created or not.
+// No span is returned when the platform marked the invocation's trace as not sampled.
+func (r *telemetryAPIReceiver) reportInvocation(record map[string]any) (ptrace.Traces, bool) {
+	requestID := r.getRecordRequestId(record)
+	if requestID == "" {
+		return ptrace.Traces{}, false
+	}
+	inv, ok := r.invocations[requestID]
+	if !ok {
+		return ptrace.Traces{}, false
+	}
+	delete(r.invocations, requestID)
+	inv.setTracing(record)
+
+	if inv.start.IsZero() || (inv.tracing != nil && !inv.tracing.sampled) {
+		return ptrace.Traces{}, false
+	}
+	if inv.end.IsZero() {
+		// platform.runtimeDone was not seen, fall back to the duration from the report.
+		metrics, _ := record["metrics"].(map[string]any)
+		durationMs, ok := metrics[string(telemetryapi.MetricDurationMs)].(float64)
+		if !ok {
+			return ptrace.Traces{}, false
+		}
+		inv.end = inv.start.Add(time.Duration(durationMs * float64(time.Millisecond)))
+	}
+	if inv.status == "" {
+		inv.status, _ = record["status"].(string)
+		inv.errorType, _ = record["errorType"].(string)
+	}
+	return r.createPlatformInvokeSpan(inv, record), true
+}
+
+// setTracing keeps the first trace context the platform attached to one of the invocation's events.
+func (inv *invocation) setTracing(record map[string]any) {
+	if inv.tracing != nil {
+		return
+	}
+	if tc, ok := parseTracing(record); ok {
+		inv.tracing = &tc
+	}
+}
+
+// trackInFlight maintains the set of invocations between their platform.start and platform.runtimeDone events,
+// and the gauge of its size. The platform.report event also closes the invocation in case platform.runtimeDone
+// was missed.
+func (r *telemetryAPIReceiver) trackInFlight(eventType string, record map[string]any) {
+	if r.updateInFlight(eventType, record) {
+		r.faaSMetricBuilders.inFlightMetric.Set(int64(len(r.inFlight)))
+	}
+}
+
+// updateInFlight maintains the set of invocations in flight, and reports whether it changed.
+func (r *telemetryAPIReceiver) updateInFlight(eventType string, record map[string]any) bool {
+	requestID := r.getRecordRequestId(record)
+	if requestID == "" {
+		return false
+	}
+	switch eventType {
+	case string(telemetryapi.PlatformStart):
+		r.inFlight[requestID] = struct{}{}
+	case string(telemetryapi.PlatformRuntimeDone), string(telemetryapi.PlatformReport):
+		if _, ok := r.inFlight[requestID]; !ok {
+			return false
+		}
+		delete(r.inFlight, requestID)
+	default:
+		return false
+	}
+	return true
+}
+
+// inFlightRequestID returns the request ID of the only invocation in flight, or "" if there is not exactly one.
+func (r *telemetryAPIReceiver) inFlightRequestID() string {
+	if len(r.inFlight) != 1 {
+		return ""
+	}
+	for requestID := range r.inFlight {
+		return requestID
+	}
+	return ""
+}
+
+// maxInvocationAge is the time after which the state of an invocation whose platform.report event was not received,
+// e.g. because Lambda dropped it, is forgotten. Lambda stops invocations after 15 minutes.
+const maxInvocationAge = 15 * time.Minute
+
+// expireInvocations forgets the state of the invocations that were already tracked by the previous expiry, at least
+// maxInvocationAge ago, so that invocations without a platform.report event do not accumulate.
+func (r *telemetryAPIReceiver) expireInvocations(now time.Time) {
+	if now.Sub(r.lastExpiry) < maxInvocationAge {
+		return
+	}
+	inFlight := len(r.inFlight)
+	for requestID := range r.expiring {
+		delete(r.invocations, requestID)
+		delete(r.runtimeDurations, requestID)
+		delete(r.logTraceContexts, requestID)
+		delete(r.inFlight, requestID)
+		delete(r.oomMetrics.detected, requestID)
+		delete(r.oomLogs.detected, requestID)
+	}
+	if len(r.inFlight) != inFlight {
+		r.faaSMetricBuilders.inFlightMetric.Set(int64(len(r.inFlight)))
+	}
+	r.lastExpiry = now
+	r.expiring = make(map[string]struct{})
+	for requestID := range r.invocations {
+		r.expiring[requestID] = struct{}{}
+	}
+	for requestID := range r.runtimeDurations {
+		r.expiring[requestID] = struct{}{}
+	}
+	for requestID := range r.logTraceContexts {
+		r.expiring[requestID] = struct{}{}
+	}
+	for requestID := range r.inFlight {
+		r.expiring[requestID] = struct{}{}
+	}
+	for requestID := range r.oomMetrics.detected {
+		r.expiring[requestID] = struct{}{}
+	}
+	for requestID := range r.oomLogs.detected {
+		r.expiring[requestID] = struct{}{}
+	}
+}
+
+func (r *telemetryAPIReceiver) getOrCreateInvocation(requestID string) *invocation {
+	inv, ok := r.invocations[requestID]
+	if !ok {
+		inv = &invocation{requestID: requestID}
+		r.invocations[requestID] = inv
+	}
+	return inv
+}
+
+func (r *telemetryAPIReceiver) createPlatformInvokeSpan(inv *invocation, report map[string]any) ptrace.Traces {
+	traceData := ptrace.NewTraces()
+	rs := traceData.ResourceSpans().AppendEmpty()
+	r.resource.CopyTo(rs.Resource())
+
+	ss := rs.ScopeSpans().AppendEmpty()
+	ss.Scope().SetName(scopeName)
+	span := ss.Spans().AppendEmpty()
+	r.setSpanContext(span, inv.tracing, inv.requestID, string(telemetryapi.PlatformStart), inv.start.Format(time.RFC3339Nano))
+	span.SetName(fmt.Sprintf("invoke %s", r.faasName))
+	span.SetKind(ptrace.SpanKindInternal)
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(inv.start))
+	span.SetEndTimestamp(pcommon.NewTimestampFromTime(inv.end))
+	span.Attributes().PutStr(string(semconv.FaaSInvocationIDKey), inv.requestID)
+
+	if metrics, ok := report["metrics"].(map[string]any); ok {
+		if billedDurationMs, ok := metrics[string(telemetryapi.MetricBilledDurationMs)].(float64); ok {
+			span.Attributes().PutDouble(attributeBilledDurationMs, billedDurationMs)
+		}
+		if maxMemoryUsedMB, ok := metrics[string(telemetryapi.MetricMaxMemoryUsedMB)].(float64); ok {
+			span.Attributes().PutDouble(attributeMaxMemoryUsedMB, maxMemoryUsedMB)
+		}
+		if memorySizeMB, ok := metrics[string(telemetryapi.MetricMemorySizeMB)].(float64); ok {
+			span.Attributes().PutDouble(attributeMemorySizeMB, memorySizeMB)
+		}
+		if initDurationMs, ok := metrics[string(telemetryapi.MetricInitDurationMs)].(float64); ok {
+			span.Attributes().PutDouble(attributeInitDurationMs, initDurationMs)
+			span.Attributes().PutBool(string(semconv.FaaSColdstartKey), true)
+		}
+	}
+
+	if inv.status != "" && inv.status != telemetrySuccessStatus {
+		span.Status().SetCode(ptrace.StatusCodeError)
+		if inv.errorType != "" {
+			span.Attributes().PutStr(string(semconv.ErrorTypeKey), inv.errorType)
+		} else {
+			span.Attributes().PutStr(string(semconv.ErrorTypeKey), inv.status)
+		}
+	}
+
+	appendExceptionEvents(span, inv.exceptions)
+	r.appendPlatformChildSpans(ss, span.TraceID(), span.SpanID(), inv.spans)
+	return traceData
+}
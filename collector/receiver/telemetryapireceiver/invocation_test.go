@@ -0,0 +1,343 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-lambda/collector/lambdalifecycle"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+func TestInvocationSpan(t *testing.T) {
+	const requestID = "34472c47-5ff0-4df5-a9ad-03776afa5473"
+
+	testCases := []struct {
+		desc              string
+		start             string
+		runtimeDone       map[string]any
+		runtimeDoneTime   string
+		report            map[string]any
+		expectSpan        bool
+		expectedDuration  time.Duration
+		expectedStatus    ptrace.StatusCode
+		expectedErrorType string
+		expectedAttrs     map[string]any
+	}{
+		{
+			desc:            "successful invocation",
+			start:           "2022-10-12T00:03:50.000Z",
+			runtimeDoneTime: "2022-10-12T00:03:50.250Z",
+			runtimeDone: map[string]any{
+				"requestId": requestID,
+				"status":    "success",
+			},
+			report: map[string]any{
+				"requestId": requestID,
+				"status":    "success",
+				"metrics": map[string]any{
+					"durationMs":       250.0,
+					"billedDurationMs": 251.0,
+					"memorySizeMB":     128.0,
+					"maxMemoryUsedMB":  64.0,
+				},
+			},
+			expectSpan:       true,
+			expectedDuration: 250 * time.Millisecond,
+			expectedStatus:   ptrace.StatusCodeUnset,
+			expectedAttrs: map[string]any{
+				string(semconv.FaaSInvocationIDKey): requestID,
+				attributeBilledDurationMs:           251.0,
+				attributeMemorySizeMB:               128.0,
+				attributeMaxMemoryUsedMB:            64.0,
+			},
+		},
+		{
+			desc:            "failed invocation with error type",
+			start:           "2022-10-12T00:03:50.000Z",
+			runtimeDoneTime: "2022-10-12T00:03:51.000Z",
+			runtimeDone: map[string]any{
+				"requestId": requestID,
+				"status":    "error",
+				"errorType": "Runtime.ExitError",
+			},
+			report: map[string]any{
+				"requestId": requestID,
+				"status":    "error",
+				"metrics": map[string]any{
+					"durationMs":     1000.0,
+					"initDurationMs": 300.0,
+				},
+			},
+			expectSpan:        true,
+			expectedDuration:  time.Second,
+			expectedStatus:    ptrace.StatusCodeError,
+			expectedErrorType: "Runtime.ExitError",
+			expectedAttrs: map[string]any{
+				attributeInitDurationMs:          300.0,
+				string(semconv.FaaSColdstartKey): true,
+			},
+		},
+		{
+			desc:  "timeout without runtimeDone falls back to report duration",
+			start: "2022-10-12T00:03:50.000Z",
+			report: map[string]any{
+				"requestId": requestID,
+				"status":    "timeout",
+				"metrics": map[string]any{
+					"durationMs": 3000.0,
+				},
+			},
+			expectSpan:        true,
+			expectedDuration:  3 * time.Second,
+			expectedStatus:    ptrace.StatusCodeError,
+			expectedErrorType: "timeout",
+		},
+		{
+			desc: "report without start",
+			report: map[string]any{
+				"requestId": requestID,
+				"status":    "success",
+			},
+			expectSpan: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+			require.NoError(t, err)
+			r.faasName = "test-func"
+
+			if tc.start != "" {
+				r.startInvocation(tc.start, map[string]any{"requestId": requestID})
+			}
+			if tc.runtimeDone != nil {
+				r.finishInvocation(tc.runtimeDoneTime, tc.runtimeDone)
+			}
+			td, ok := r.reportInvocation(tc.report)
+			require.Equal(t, tc.expectSpan, ok)
+			require.Empty(t, r.invocations)
+			if !tc.expectSpan {
+				return
+			}
+
+			require.Equal(t, 1, td.SpanCount())
+			span := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+			require.Equal(t, "invoke test-func", span.Name())
+			require.Equal(t, tc.expectedDuration, span.EndTimestamp().AsTime().Sub(span.StartTimestamp().AsTime()))
+			require.Equal(t, tc.expectedStatus, span.Status().Code())
+			errorType, ok := span.Attributes().Get(string(semconv.ErrorTypeKey))
+			if tc.expectedErrorType != "" {
+				require.True(t, ok)
+				require.Equal(t, tc.expectedErrorType, errorType.Str())
+			} else {
+				require.False(t, ok)
+			}
+			for k, v := range tc.expectedAttrs {
+				attr, ok := span.Attributes().Get(k)
+				require.True(t, ok, "missing attribute %s", k)
+				require.Equal(t, v, attr.AsRaw())
+			}
+		})
+	}
+}
+
+func TestInvocationSpanTracing(t *testing.T) {
+	const requestID = "34472c47-5ff0-4df5-a9ad-03776afa5473"
+
+	testCases := []struct {
+		desc       string
+		sampled    string
+		expectSpan bool
+	}{
+		{
+			desc:       "sampled",
+			sampled:    "1",
+			expectSpan: true,
+		},
+		{
+			desc:       "not sampled",
+			sampled:    "0",
+			expectSpan: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+			require.NoError(t, err)
+
+			r.startInvocation("2022-10-12T00:03:50.000Z", map[string]any{
+				"requestId": requestID,
+				"tracing": map[string]any{
+					"spanId": "3a6fd4da3b5f2d78",
+					"type":   "X-Amzn-Trace-Id",
+					"value":  "Root=1-5e1b4151-43a0913a12345678901234f5;Parent=53995c3f42cd8ad8;Sampled=" + tc.sampled,
+				},
+			})
+			r.finishInvocation("2022-10-12T00:03:50.100Z", map[string]any{"requestId": requestID, "status": "success"})
+			td, ok := r.reportInvocation(map[string]any{"requestId": requestID})
+			require.Equal(t, tc.expectSpan, ok)
+			if !tc.expectSpan {
+				return
+			}
+
+			span := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+			require.Equal(t, "5e1b415143a0913a12345678901234f5", span.TraceID().String())
+			require.Equal(t, "53995c3f42cd8ad8", span.ParentSpanID().String())
+			require.Equal(t, "3a6fd4da3b5f2d78", span.SpanID().String())
+		})
+	}
+}
+
+func TestInvocationSpanRuntimePhases(t *testing.T) {
+	const requestID = "34472c47-5ff0-4df5-a9ad-03776afa5473"
+
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	r.startInvocation("2022-10-12T00:03:50.000Z", map[string]any{"requestId": requestID})
+	r.finishInvocation("2022-10-12T00:03:50.300Z", map[string]any{
+		"requestId": requestID,
+		"status":    "success",
+		"spans": []any{
+			map[string]any{"name": "responseLatency", "start": "2022-10-12T00:03:50.010Z", "durationMs": 200.0},
+			map[string]any{"name": "responseDuration", "start": "2022-10-12T00:03:50.210Z", "durationMs": 40.0},
+			map[string]any{"name": "runtimeOverhead", "start": "2022-10-12T00:03:50.250Z", "durationMs": 50.0},
+			map[string]any{"name": "missingDuration", "start": "2022-10-12T00:03:50.250Z"},
+		},
+	})
+	td, ok := r.reportInvocation(map[string]any{"requestId": requestID})
+	require.True(t, ok)
+	require.Equal(t, 4, td.SpanCount())
+
+	spans := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+	parent := spans.At(0)
+	expected := map[string]time.Duration{
+		"responseLatency":  200 * time.Millisecond,
+		"responseDuration": 40 * time.Millisecond,
+		"runtimeOverhead":  50 * time.Millisecond,
+	}
+	for i := 1; i < spans.Len(); i++ {
+		child := spans.At(i)
+		duration, ok := expected[child.Name()]
+		require.True(t, ok, "unexpected span %s", child.Name())
+		require.Equal(t, duration, child.EndTimestamp().AsTime().Sub(child.StartTimestamp().AsTime()))
+		require.Equal(t, parent.TraceID(), child.TraceID())
+		require.Equal(t, parent.SpanID(), child.ParentSpanID())
+	}
+}
+
+func TestTrackInFlight(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	r.trackInFlight("platform.start", map[string]any{"requestId": "a"})
+	require.Equal(t, "a", r.inFlightRequestID())
+	r.trackInFlight("platform.start", map[string]any{"requestId": "b"})
+	require.Equal(t, "", r.inFlightRequestID())
+	r.trackInFlight("platform.runtimeDone", map[string]any{"requestId": "a"})
+	require.Equal(t, "b", r.inFlightRequestID())
+	r.trackInFlight("platform.report", map[string]any{"requestId": "b"})
+	require.Empty(t, r.inFlight)
+
+	sm := pmetric.NewScopeMetrics()
+	r.faaSMetricBuilders.inFlightMetric.AppendDataPoints(sm, 0)
+	require.Equal(t, 1, sm.Metrics().Len())
+	require.Equal(t, InFlightName, sm.Metrics().At(0).Name())
+	require.Equal(t, int64(2), sm.Metrics().At(0).Gauge().DataPoints().At(0).IntValue())
+}
+
+func TestExpireInvocations(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+	now := time.Now()
+
+	// The platform.report event of "a" never arrives.
+	r.startInvocation("2022-10-12T00:03:50.000Z", map[string]any{"requestId": "a"})
+	r.expireInvocations(now)
+	require.Contains(t, r.invocations, "a")
+
+	r.startInvocation("2022-10-12T00:03:51.000Z", map[string]any{"requestId": "b"})
+	r.expireInvocations(now.Add(maxInvocationAge - time.Second))
+	require.Len(t, r.invocations, 2)
+
+	r.expireInvocations(now.Add(maxInvocationAge))
+	require.NotContains(t, r.invocations, "a")
+	require.Contains(t, r.invocations, "b")
+
+	_, ok := r.reportInvocation(map[string]any{
+		"requestId": "b",
+		"status":    "success",
+		"metrics":   map[string]any{"durationMs": 10.0},
+	})
+	require.True(t, ok)
+	r.expireInvocations(now.Add(2 * maxInvocationAge))
+	require.Empty(t, r.invocations)
+}
+
+func TestExpireInFlight(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+	now := time.Now()
+
+	// Neither platform.runtimeDone nor platform.report of "a" arrives.
+	r.trackInFlight("platform.start", map[string]any{"requestId": "a"})
+	r.expireInvocations(now)
+	r.trackInFlight("platform.start", map[string]any{"requestId": "b"})
+	require.Equal(t, "", r.inFlightRequestID())
+
+	r.expireInvocations(now.Add(maxInvocationAge))
+	require.Equal(t, "b", r.inFlightRequestID())
+}
+
+func TestManagedInstancesLogCorrelation(t *testing.T) {
+	body := `[
+		{"time":"2022-10-12T00:03:50.000Z", "type":"platform.start", "record": {"requestId":"a"}},
+		{"time":"2022-10-12T00:03:50.001Z", "type":"function", "record": "only a"},
+		{"time":"2022-10-12T00:03:50.002Z", "type":"platform.start", "record": {"requestId":"b"}},
+		{"time":"2022-10-12T00:03:50.003Z", "type":"function", "record": "a or b"},
+		{"time":"2022-10-12T00:03:50.004Z", "type":"platform.runtimeDone", "record": {"requestId":"a", "status":"success"}},
+		{"time":"2022-10-12T00:03:50.005Z", "type":"function", "record": "only b"}
+	]`
+
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+	r.lambdaInitType = lambdalifecycle.LambdaManagedInstances
+	sink := &consumertest.LogsSink{}
+	r.registerLogsConsumer(sink)
+	r.httpHandler(httptest.NewRecorder(), httptest.NewRequest("POST", "http://localhost/", strings.NewReader(body)))
+
+	require.Len(t, sink.AllLogs(), 1)
+	records := logRecords(t, sink.AllLogs()[0])
+	attributed := map[string]string{}
+	for i := 0; i < records.Len(); i++ {
+		if records.At(i).Body().Str() == "" {
+			continue
+		}
+		invocationID, _ := records.At(i).Attributes().Get(string(semconv.FaaSInvocationIDKey))
+		attributed[records.At(i).Body().Str()] = invocationID.Str()
+	}
+	require.Equal(t, "a", attributed["only a"])
+	require.Equal(t, "", attributed["a or b"])
+	require.Equal(t, "b", attributed["only b"])
+}
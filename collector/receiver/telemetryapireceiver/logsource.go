@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"slices"
+
+	"github.com/open-telemetry/opentelemetry-lambda/collector/internal/telemetryapi"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	attributeLogSource     = "aws.lambda.log.source"
+	attributeExtensionName = "aws.lambda.extension.name"
+)
+
+// sourceLogs creates the log records of each source of events, platform, function or extension, in a resource
+// of their own, so that the sources can be told apart and routed separately. Records keep the order of their
+// events: consecutive records of the same source share a resource, and a change of source starts a new one.
+type sourceLogs struct {
+	r        *telemetryAPIReceiver
+	logs     plog.Logs
+	source   telemetryapi.EventType
+	scopeLog plog.ScopeLogs
+}
+
+func (r *telemetryAPIReceiver) newSourceLogs() *sourceLogs {
+	return &sourceLogs{
+		r:    r,
+		logs: plog.NewLogs(),
+	}
+}
+
+// scopeLogs returns the scope the next record of the source is appended to.
+func (s *sourceLogs) scopeLogs(source telemetryapi.EventType) plog.ScopeLogs {
+	if s.logs.ResourceLogs().Len() > 0 && s.source == source {
+		return s.scopeLog
+	}
+	resourceLog := s.logs.ResourceLogs().AppendEmpty()
+	s.r.resource.CopyTo(resourceLog.Resource())
+	resourceLog.Resource().Attributes().PutStr(attributeLogSource, string(source))
+	if name := s.r.extensionName(); name != "" && source == telemetryapi.Extension {
+		resourceLog.Resource().Attributes().PutStr(attributeExtensionName, name)
+	}
+	s.source = source
+	s.scopeLog = resourceLog.ScopeLogs().AppendEmpty()
+	s.scopeLog.Scope().SetName(scopeName)
+	return s.scopeLog
+}
+
+// trackExtension remembers the name of an extension reported by a platform.extension event.
+func (r *telemetryAPIReceiver) trackExtension(record map[string]any) {
+	if name, _ := record["name"].(string); name != "" && !slices.Contains(r.extensions, name) {
+		r.extensions = append(r.extensions, name)
+	}
+}
+
+// extensionName returns the name of the extension extension logs come from. The Telemetry API does not identify
+// the extension that logged a line, so the name is only known when a single extension is registered.
+func (r *telemetryAPIReceiver) extensionName() string {
+	if len(r.extensions) != 1 {
+		return ""
+	}
+	return r.extensions[0]
+}
+
+// dropLogSources removes the records of the sources not configured in log_sources.
+func (r *telemetryAPIReceiver) dropLogSources(logs plog.Logs) {
+	if len(r.logSources) == 0 {
+		return
+	}
+	logs.ResourceLogs().RemoveIf(func(resourceLog plog.ResourceLogs) bool {
+		source, ok := resourceLog.Resource().Attributes().Get(attributeLogSource)
+		return ok && !slices.Contains(r.logSources, source.Str())
+	})
+}
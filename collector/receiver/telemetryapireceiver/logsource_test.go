@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+// logRecords returns the log records of all the resources in order, checking that each resource has a source
+// and a single scope of the receiver.
+func logRecords(t *testing.T, logs plog.Logs) plog.LogRecordSlice {
+	t.Helper()
+	records := plog.NewLogRecordSlice()
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		resourceLog := logs.ResourceLogs().At(i)
+		_, ok := resourceLog.Resource().Attributes().Get(attributeLogSource)
+		require.True(t, ok)
+		require.Equal(t, 1, resourceLog.ScopeLogs().Len())
+		scopeLog := resourceLog.ScopeLogs().At(0)
+		require.Equal(t, scopeName, scopeLog.Scope().Name())
+		for j := 0; j < scopeLog.LogRecords().Len(); j++ {
+			scopeLog.LogRecords().At(j).CopyTo(records.AppendEmpty())
+		}
+	}
+	return records
+}
+
+// logSourceBodies returns the bodies of the log records by source.
+func logSourceBodies(logs plog.Logs) map[string][]string {
+	bodies := make(map[string][]string)
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		resourceLog := logs.ResourceLogs().At(i)
+		source, _ := resourceLog.Resource().Attributes().Get(attributeLogSource)
+		records := resourceLog.ScopeLogs().At(0).LogRecords()
+		for j := 0; j < records.Len(); j++ {
+			bodies[source.Str()] = append(bodies[source.Str()], records.At(j).Body().AsString())
+		}
+	}
+	return bodies
+}
+
+const logSourcesRequest = `[
+	{"time":"2024-01-02T15:04:05.000Z","type":"platform.extension","record":{"name":"collector","state":"Ready","events":["INVOKE"]}},
+	{"time":"2024-01-02T15:04:05.001Z","type":"function","record":"from function"},
+	{"time":"2024-01-02T15:04:05.002Z","type":"extension","record":"from extension"},
+	{"time":"2024-01-02T15:04:05.003Z","type":"function","record":"from function again"}
+]`
+
+func TestCreateLogsSources(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+	logsSink := &consumertest.LogsSink{}
+	r.registerLogsConsumer(logsSink)
+
+	require.Equal(t, 200, postBody(r, logSourcesRequest))
+
+	// The records keep the order of their events, a change of source starting a new resource.
+	logs := logsSink.AllLogs()[0]
+	var sources []string
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		source, _ := logs.ResourceLogs().At(i).Resource().Attributes().Get(attributeLogSource)
+		sources = append(sources, source.Str())
+	}
+	require.Equal(t, []string{platform, function, extension, function}, sources)
+	require.Equal(t, map[string][]string{
+		platform:  {"EXTENSION Name: collector State: Ready Events: [INVOKE]"},
+		function:  {"from function", "from function again"},
+		extension: {"from extension"},
+	}, logSourceBodies(logs))
+
+	extensionResource := logs.ResourceLogs().At(2).Resource()
+	name, ok := extensionResource.Attributes().Get(attributeExtensionName)
+	require.True(t, ok)
+	require.Equal(t, "collector", name.Str())
+	_, ok = logs.ResourceLogs().At(1).Resource().Attributes().Get(attributeExtensionName)
+	require.False(t, ok)
+}
+
+func TestCreateLogsExtensionName(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	r.trackExtension(map[string]any{"name": "collector"})
+	r.trackExtension(map[string]any{"name": "collector"})
+	require.Equal(t, "collector", r.extensionName())
+
+	// The extension that logged a line cannot be told once several are registered.
+	r.trackExtension(map[string]any{"name": "datadog-agent"})
+	require.Equal(t, "", r.extensionName())
+}
+
+func TestLogSources(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		sources  []string
+		expected map[string][]string
+	}{
+		{
+			desc:    "function only",
+			sources: []string{function},
+			expected: map[string][]string{
+				function: {"from function", "from function again"},
+			},
+		},
+		{
+			desc:    "extension and platform",
+			sources: []string{extension, platform},
+			expected: map[string][]string{
+				platform:  {"EXTENSION Name: collector State: Ready Events: [INVOKE]"},
+				extension: {"from extension"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := newTelemetryAPIReceiver(&Config{LogSources: tc.sources}, receivertest.NewNopSettings(Type))
+			require.NoError(t, err)
+			logsSink := &consumertest.LogsSink{}
+			r.registerLogsConsumer(logsSink)
+
+			require.Equal(t, 200, postBody(r, logSourcesRequest))
+			require.Equal(t, tc.expected, logSourceBodies(logsSink.AllLogs()[0]))
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-lambda/collector/internal/telemetryapi"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+const (
+	// outOfMemoryErrorType is part of the error type Lambda reports for invocations that ran out of memory,
+	// e.g. `Runtime.OutOfMemory`.
+	outOfMemoryErrorType      = "OutOfMemory"
+	platformOutOfMemoryLogFmt = "OUT_OF_MEMORY RequestId: %s Error Type: %s"
+)
+
+// isOutOfMemory reports whether the record of a platform.runtimeDone or platform.report event describes
+// an invocation terminated for running out of memory.
+func isOutOfMemory(record map[string]any) bool {
+	status, _ := record["status"].(string)
+	if status != telemetryErrorStatus && status != telemetryFailureStatus {
+		return false
+	}
+	errorType, _ := record["errorType"].(string)
+	return strings.Contains(errorType, outOfMemoryErrorType)
+}
+
+// outOfMemoryDetector detects each out-of-memory invocation once, although both its platform.runtimeDone
+// and platform.report events describe the termination. As invocations run concurrently with Lambda Managed
+// Instances, it keeps the request IDs detected from platform.runtimeDone events until their platform.report event.
+type outOfMemoryDetector struct {
+	detected map[string]struct{}
+}
+
+func (d *outOfMemoryDetector) detect(eventType string, record map[string]any) bool {
+	requestID, _ := record["requestId"].(string)
+	switch eventType {
+	case string(telemetryapi.PlatformRuntimeDone):
+		if !isOutOfMemory(record) {
+			return false
+		}
+		if requestID == "" {
+			return true
+		}
+		if _, ok := d.detected[requestID]; ok {
+			return false
+		}
+		if d.detected == nil {
+			d.detected = make(map[string]struct{})
+		}
+		d.detected[requestID] = struct{}{}
+		return true
+	case string(telemetryapi.PlatformReport):
+		if _, ok := d.detected[requestID]; ok {
+			delete(d.detected, requestID)
+			return false
+		}
+		return isOutOfMemory(record)
+	default:
+		return false
+	}
+}
+
+// appendOutOfMemoryLog appends an error log record for the out-of-memory termination described by the event.
+// The record keeps the type of the event it is derived from, and is told apart by its error.type attribute.
+func (r *telemetryAPIReceiver) appendOutOfMemoryLog(scopeLog plog.ScopeLogs, el event, record map[string]any) {
+	requestID := r.getRecordRequestId(record)
+	if requestID == "" {
+		requestID = r.getCurrentRequestId()
+	}
+	errorType, _ := record["errorType"].(string)
+
+	logRecord := scopeLog.LogRecords().AppendEmpty()
+	logRecord.Attributes().PutStr("type", el.Type)
+	logRecord.Attributes().PutStr(string(semconv.ErrorTypeKey), errorType)
+	if requestID != "" {
+		logRecord.Attributes().PutStr(string(semconv.FaaSInvocationIDKey), requestID)
+	}
+	if t, err := time.Parse(time.RFC3339, el.Time); err == nil {
+		logRecord.SetTimestamp(pcommon.NewTimestampFromTime(t))
+	}
+	logRecord.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	logRecord.SetSeverityNumber(plog.SeverityNumberError)
+	logRecord.SetSeverityText(plog.SeverityNumberError.String())
+	logRecord.Body().SetStr(fmt.Sprintf(platformOutOfMemoryLogFmt, requestID, errorType))
+}
@@ -0,0 +1,170 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+func TestIsOutOfMemory(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		record   map[string]any
+		expected bool
+	}{
+		{
+			desc:     "runtime out of memory",
+			record:   map[string]any{"status": "error", "errorType": "Runtime.OutOfMemory"},
+			expected: true,
+		},
+		{
+			desc:     "failure status",
+			record:   map[string]any{"status": "failure", "errorType": "Runtime.OutOfMemory"},
+			expected: true,
+		},
+		{
+			desc:     "other error",
+			record:   map[string]any{"status": "error", "errorType": "Runtime.ExitError"},
+			expected: false,
+		},
+		{
+			desc:     "timeout",
+			record:   map[string]any{"status": "timeout"},
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expected, isOutOfMemory(tc.record))
+		})
+	}
+}
+
+func TestOutOfMemoryDetector(t *testing.T) {
+	oom := func(requestID string) map[string]any {
+		return map[string]any{"requestId": requestID, "status": "error", "errorType": "Runtime.OutOfMemory"}
+	}
+
+	var d outOfMemoryDetector
+	require.True(t, d.detect("platform.runtimeDone", oom("a")))
+	require.False(t, d.detect("platform.report", oom("a")))
+	require.False(t, d.detect("platform.initReport", oom("b")))
+	require.True(t, d.detect("platform.report", oom("b")))
+	require.Empty(t, d.detected)
+}
+
+func TestOutOfMemoryDetectorInterleaved(t *testing.T) {
+	oom := func(requestID string) map[string]any {
+		return map[string]any{"requestId": requestID, "status": "error", "errorType": "Runtime.OutOfMemory"}
+	}
+
+	// With Lambda Managed Instances the events of concurrent invocations interleave.
+	var d outOfMemoryDetector
+	require.True(t, d.detect("platform.runtimeDone", oom("a")))
+	require.True(t, d.detect("platform.runtimeDone", oom("b")))
+	require.False(t, d.detect("platform.report", oom("a")))
+	require.False(t, d.detect("platform.report", oom("b")))
+	require.Empty(t, d.detected)
+}
+
+func TestRecordMemoryMetrics(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	r.recordMetrics([]event{
+		{
+			Type: "platform.report",
+			Record: map[string]any{
+				"requestId": "ok",
+				"status":    "success",
+				"metrics":   map[string]any{"memorySizeMB": 256.0, "maxMemoryUsedMB": 64.0},
+			},
+		},
+		{
+			Type:   "platform.runtimeDone",
+			Record: map[string]any{"requestId": "oom", "status": "error", "errorType": "Runtime.OutOfMemory"},
+		},
+		{
+			Type: "platform.report",
+			Record: map[string]any{
+				"requestId": "oom",
+				"status":    "error",
+				"errorType": "Runtime.OutOfMemory",
+				"metrics":   map[string]any{"memorySizeMB": 256.0, "maxMemoryUsedMB": 256.0},
+			},
+		},
+	})
+
+	c := &mockConsumer{}
+	r.registerMetricsConsumer(c)
+	require.NoError(t, r.flushMetrics(context.Background()))
+	require.Len(t, c.metricBatches, 1)
+
+	found := map[string]pmetric.Metric{}
+	sm := c.metricBatches[0].ResourceMetrics().At(0).ScopeMetrics().At(0)
+	for i := 0; i < sm.Metrics().Len(); i++ {
+		found[sm.Metrics().At(i).Name()] = sm.Metrics().At(i)
+	}
+
+	require.Contains(t, found, MemUtilizationName)
+	utilization := found[MemUtilizationName].Histogram().DataPoints().At(0)
+	require.Equal(t, uint64(2), utilization.Count())
+	require.Equal(t, 1.25, utilization.Sum())
+	require.Contains(t, found, OutOfMemoryName)
+	require.Equal(t, int64(1), found[OutOfMemoryName].Sum().DataPoints().At(0).IntValue())
+}
+
+func TestCreateLogsOutOfMemory(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	logs, err := r.createLogs([]event{
+		{
+			Time:   "2022-10-12T00:03:50.000Z",
+			Type:   "platform.runtimeDone",
+			Record: map[string]any{"requestId": "oom", "status": "error", "errorType": "Runtime.OutOfMemory"},
+		},
+		{
+			Time:   "2022-10-12T00:03:50.010Z",
+			Type:   "platform.report",
+			Record: map[string]any{"requestId": "oom", "status": "error", "errorType": "Runtime.OutOfMemory"},
+		},
+	})
+	require.NoError(t, err)
+
+	var oomRecords []plog.LogRecord
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < records.Len(); i++ {
+		if _, ok := records.At(i).Attributes().Get(string(semconv.ErrorTypeKey)); ok {
+			oomRecords = append(oomRecords, records.At(i))
+		}
+	}
+	require.Len(t, oomRecords, 1)
+	eventType, _ := oomRecords[0].Attributes().Get("type")
+	require.Equal(t, "platform.runtimeDone", eventType.Str())
+	require.Equal(t, plog.SeverityNumberError, oomRecords[0].SeverityNumber())
+	require.Equal(t, "OUT_OF_MEMORY RequestId: oom Error Type: Runtime.OutOfMemory", oomRecords[0].Body().Str())
+	errorType, _ := oomRecords[0].Attributes().Get(string(semconv.ErrorTypeKey))
+	require.Equal(t, "Runtime.OutOfMemory", errorType.Str())
+	invocationID, _ := oomRecords[0].Attributes().Get(string(semconv.FaaSInvocationIDKey))
+	require.Equal(t, "oom", invocationID.Str())
+}
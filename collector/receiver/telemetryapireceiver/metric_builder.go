@@ -26,9 +26,91 @@ import (
 const MiB = float64(1 << 20)
 const GiB = float64(1 << 30)
 
+// Names, descriptions and units of the Lambda specific metrics, which semantic conventions do not define.
+const (
+	RestoreDurationName        = "aws.lambda.restore_duration"
+	RestoreDurationDescription = "Measures the duration of the function's restore from a SnapStart snapshot"
+	RestoreDurationUnit        = "s"
+
+	BilledDurationName        = "aws.lambda.billed_duration"
+	BilledDurationDescription = "Measures the billed duration of the function's logic execution"
+	BilledDurationUnit        = "s"
+
+	GBSecondsName        = "aws.lambda.gb_seconds"
+	GBSecondsDescription = "Number of GB-seconds of compute billed for the function's invocations"
+	GBSecondsUnit        = "GBy.s"
+
+	EstimatedCostName        = "aws.lambda.estimated_cost"
+	EstimatedCostDescription = "Estimated cost of the function's invocations, in the currency of the configured prices"
+	EstimatedCostUnit        = "1"
+
+	ExtensionOverheadName        = "aws.lambda.extension_overhead"
+	ExtensionOverheadDescription = "Measures the time extensions add to an invocation after the function's logic execution"
+	ExtensionOverheadUnit        = "s"
+
+	InFlightName        = "aws.lambda.invocations_in_flight"
+	InFlightDescription = "Maximum number of invocations running at the same time since the previous export"
+	InFlightUnit        = "{invocation}"
+
+	MemUtilizationName        = "aws.lambda.mem_utilization"
+	MemUtilizationDescription = "Ratio of the maximum memory used by the function to its configured memory size"
+	MemUtilizationUnit        = "1"
+
+	OutOfMemoryName        = "aws.lambda.out_of_memory"
+	OutOfMemoryDescription = "Number of invocations terminated because the function ran out of memory"
+	OutOfMemoryUnit        = "{invocation}"
+
+	LogRecordsName        = "aws.lambda.log_records"
+	LogRecordsDescription = "Number of log records written by the function and its extensions"
+	LogRecordsUnit        = "{record}"
+)
+
+// Names, descriptions and units of the metrics describing the health of the telemetry pipeline.
+const (
+	LogsDroppedRecordsName        = "aws.lambda.telemetry.dropped_records"
+	LogsDroppedRecordsDescription = "Number of log records Lambda dropped before delivering them to the Telemetry API"
+	LogsDroppedRecordsUnit        = "{record}"
+
+	LogsDroppedBytesName        = "aws.lambda.telemetry.dropped_bytes"
+	LogsDroppedBytesDescription = "Number of bytes of log records Lambda dropped before delivering them to the Telemetry API"
+	LogsDroppedBytesUnit        = "By"
+
+	SubscriptionsName        = "aws.lambda.telemetry.subscriptions"
+	SubscriptionsDescription = "Number of Telemetry API subscription state changes reported by Lambda"
+	SubscriptionsUnit        = "{subscription}"
+
+	EventsName        = "aws.lambda.telemetry.events"
+	EventsDescription = "Number of events received from the Telemetry API"
+	EventsUnit        = "{event}"
+
+	UnmarshalFailuresName        = "aws.lambda.telemetry.unmarshal_failures"
+	UnmarshalFailuresDescription = "Number of Telemetry API requests whose body could not be unmarshalled"
+	UnmarshalFailuresUnit        = "{request}"
+
+	ConsumerErrorsName        = "aws.lambda.telemetry.consumer_errors"
+	ConsumerErrorsDescription = "Number of errors returned by the next consumer of the receiver"
+	ConsumerErrorsUnit        = "{error}"
+)
+
+// Attributes of the telemetry pipeline and log record metrics.
+const (
+	attributeEventType         = "aws.lambda.event.type"
+	attributeSignal            = "aws.lambda.telemetry.signal"
+	attributeSubscriptionState = "aws.lambda.subscription.state"
+	attributeSeverityNumber    = "aws.lambda.log.severity_number"
+)
+
+const (
+	// overflowAttributeKey marks the data point that aggregates the series recorded past a builder's series cap.
+	overflowAttributeKey = "otel.metric.overflow"
+	// defaultMaxSeries is the series cap applied when dimensions are configured without an explicit cap.
+	defaultMaxSeries = 100
+)
+
 var DefaultHistogramBounds = []float64{0.0, 5.0, 10.0, 25.0, 50.0, 75.0, 100.0, 250.0, 500.0, 750.0, 1000.0, 2500.0, 5000.0, 7500.0, 10000.0}
 var DurationHistogramBounds = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
 var MemUsageHistogramBounds = []float64{16 * MiB, 32 * MiB, 64 * MiB, 128 * MiB, 256 * MiB, 512 * MiB, 768 * MiB, 1 * GiB, 2 * GiB, 3 * GiB, 4 * GiB, 6 * GiB, 8 * GiB}
+var MemUtilizationHistogramBounds = []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 0.95, 1}
 
 type histogramDataPoint struct {
 	attributes  pcommon.Map
@@ -36,7 +118,8 @@ type histogramDataPoint struct {
 	total       uint64
 	sum         float64
 	startTime   pcommon.Timestamp
-	lastUpdated uint64 // epoch when this data point was last updated
+	lastUpdated uint64                // epoch when this data point was last updated
+	exponential *exponentialHistogram // buckets of exponential histograms, nil for explicit bucket histograms
 }
 
 func newHistogramDataPoint(attrs pcommon.Map, numBuckets int, startTime pcommon.Timestamp, epoch uint64) *histogramDataPoint {
@@ -56,7 +139,12 @@ type HistogramMetricBuilder struct {
 	dataPoints  map[[16]byte]*histogramDataPoint
 	startTime   pcommon.Timestamp
 	temporality pmetric.AggregationTemporality
-	epoch       uint64 // current epoch counter
+	epoch       uint64   // current epoch counter
+	dimensions  []string // attributes kept by RecordWithDimensions
+	maxSeries   int      // maximum number of series before recording to the overflow series, 0 for no limit
+	exponential bool     // whether data points are exported as base-2 exponential histograms
+	maxSize     int      // maximum number of buckets of exponential histograms
+	maxScale    int32    // initial scale of exponential histograms
 }
 
 func NewHistogramMetricBuilder(name string, description string, unit string, bounds []float64, startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *HistogramMetricBuilder {
@@ -82,6 +170,14 @@ func NewHistogramMetricBuilder(name string, description string, unit string, bou
 	}
 }
 
+// NewExponentialHistogramMetricBuilder creates a builder of base-2 exponential histograms with at most maxSize
+// buckets for each of the positive and negative ranges, starting at maxScale.
+func NewExponentialHistogramMetricBuilder(name string, description string, unit string, maxSize int, maxScale int32, startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *HistogramMetricBuilder {
+	h := NewHistogramMetricBuilder(name, description, unit, nil, startTime, temporality)
+	h.SetExponential(maxSize, maxScale)
+	return h
+}
+
 func (h *HistogramMetricBuilder) Record(value float64) {
 	h.RecordWithAttributes(value, pcommon.NewMap())
 }
@@ -89,17 +185,56 @@ func (h *HistogramMetricBuilder) Record(value float64) {
 func (h *HistogramMetricBuilder) RecordWithAttributes(value float64, attrs pcommon.Map) {
 	key := pdatautil.MapHash(attrs)
 	dp, exists := h.dataPoints[key]
+	if !exists && h.maxSeries > 0 && len(h.dataPoints) >= h.maxSeries {
+		attrs = newOverflowAttributes()
+		key = pdatautil.MapHash(attrs)
+		dp, exists = h.dataPoints[key]
+	}
 	if !exists {
-		dp = newHistogramDataPoint(attrs, len(h.bounds)+1, h.startTime, h.epoch)
+		if h.exponential {
+			dp = newHistogramDataPoint(attrs, 0, h.startTime, h.epoch)
+			dp.exponential = newExponentialHistogram(h.maxScale)
+		} else {
+			dp = newHistogramDataPoint(attrs, len(h.bounds)+1, h.startTime, h.epoch)
+		}
 		h.dataPoints[key] = dp
 	}
 
 	dp.sum += value
 	dp.total++
-	dp.counts[sort.SearchFloat64s(h.bounds, value)]++
+	if dp.exponential != nil {
+		dp.exponential.record(value, h.maxSize)
+	} else {
+		dp.counts[sort.SearchFloat64s(h.bounds, value)]++
+	}
 	dp.lastUpdated = h.epoch
 }
 
+// RecordWithDimensions records the value with the configured dimensions selected from attrs.
+func (h *HistogramMetricBuilder) RecordWithDimensions(value float64, attrs pcommon.Map) {
+	h.RecordWithAttributes(value, selectAttributes(h.dimensions, attrs))
+}
+
+// SetDimensions sets the attributes kept by RecordWithDimensions and the maximum number of series.
+// Once the maximum is reached, values for new series are recorded to a single overflow series.
+func (h *HistogramMetricBuilder) SetDimensions(dimensions []string, maxSeries int) {
+	h.dimensions = dimensions
+	h.maxSeries = maxSeries
+}
+
+// SetBounds sets the explicit bucket bounds of the histogram. It must be called before any value is recorded.
+func (h *HistogramMetricBuilder) SetBounds(bounds []float64) {
+	h.bounds = bounds
+	h.exponential = false
+}
+
+// SetExponential switches the histogram to base-2 exponential buckets. It must be called before any value is recorded.
+func (h *HistogramMetricBuilder) SetExponential(maxSize int, maxScale int32) {
+	h.exponential = true
+	h.maxSize = maxSize
+	h.maxScale = maxScale
+}
+
 func (h *HistogramMetricBuilder) RecordWithMap(value float64, attrs map[string]any) error {
 	m := pcommon.NewMap()
 	err := m.FromRaw(attrs)
@@ -136,6 +271,21 @@ func (h *HistogramMetricBuilder) AppendDataPoints(scopeMetrics pmetric.ScopeMetr
 	metric.SetDescription(h.description)
 	metric.SetUnit(h.unit)
 
+	if h.exponential {
+		h.appendExponentialDataPoints(metric, timestamp)
+	} else {
+		h.appendExplicitDataPoints(metric, timestamp)
+	}
+
+	if h.temporality == pmetric.AggregationTemporalityDelta {
+		h.Reset(timestamp)
+	} else {
+		// For cumulative, increment epoch for next collection cycle
+		h.epoch++
+	}
+}
+
+func (h *HistogramMetricBuilder) appendExplicitDataPoints(metric pmetric.Metric, timestamp pcommon.Timestamp) {
 	hist := metric.SetEmptyHistogram()
 	hist.SetAggregationTemporality(h.temporality)
 
@@ -154,18 +304,32 @@ func (h *HistogramMetricBuilder) AppendDataPoints(scopeMetrics pmetric.ScopeMetr
 		dp.BucketCounts().FromRaw(hdp.counts)
 		dp.ExplicitBounds().FromRaw(h.bounds)
 	}
+}
 
-	if h.temporality == pmetric.AggregationTemporalityDelta {
-		h.Reset(timestamp)
-	} else {
-		// For cumulative, increment epoch for next collection cycle
-		h.epoch++
+func (h *HistogramMetricBuilder) appendExponentialDataPoints(metric pmetric.Metric, timestamp pcommon.Timestamp) {
+	hist := metric.SetEmptyExponentialHistogram()
+	hist.SetAggregationTemporality(h.temporality)
+
+	for _, hdp := range h.dataPoints {
+		// For cumulative: only export if updated in current epoch
+		if h.temporality == pmetric.AggregationTemporalityCumulative && hdp.lastUpdated != h.epoch {
+			continue
+		}
+
+		dp := hist.DataPoints().AppendEmpty()
+		hdp.attributes.CopyTo(dp.Attributes())
+		dp.SetStartTimestamp(hdp.startTime)
+		dp.SetTimestamp(timestamp)
+		dp.SetSum(hdp.sum)
+		dp.SetCount(hdp.total)
+		hdp.exponential.copyTo(dp)
 	}
 }
 
 type counterDataPoint struct {
 	attributes  pcommon.Map
 	total       int64
+	doubleTotal float64
 	startTime   pcommon.Timestamp
 	lastUpdated uint64 // epoch when this data point was last updated
 }
@@ -184,9 +348,12 @@ type CounterMetricBuilder struct {
 	unit        string
 	dataPoints  map[[16]byte]*counterDataPoint
 	isMonotonic bool
+	isDouble    bool // whether data points are exported as double values
 	temporality pmetric.AggregationTemporality
 	startTime   pcommon.Timestamp
-	epoch       uint64 // current epoch counter
+	epoch       uint64   // current epoch counter
+	dimensions  []string // attributes kept by AddWithDimensions
+	maxSeries   int      // maximum number of series before recording to the overflow series, 0 for no limit
 }
 
 func NewCounterMetricBuilder(name string, description string, unit string, isMonotonic bool, startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *CounterMetricBuilder {
@@ -207,19 +374,64 @@ func NewCounterMetricBuilder(name string, description string, unit string, isMon
 	}
 }
 
+// NewDoubleCounterMetricBuilder creates a counter whose data points are exported as double values,
+// for sums of fractional quantities.
+func NewDoubleCounterMetricBuilder(name string, description string, unit string, isMonotonic bool, startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *CounterMetricBuilder {
+	c := NewCounterMetricBuilder(name, description, unit, isMonotonic, startTime, temporality)
+	c.isDouble = true
+	return c
+}
+
 func (c *CounterMetricBuilder) Add(value int64) {
 	c.AddWithAttributes(value, pcommon.NewMap())
 }
 
 func (c *CounterMetricBuilder) AddWithAttributes(value int64, attrs pcommon.Map) {
+	dp := c.getOrCreateDataPoint(attrs)
+	dp.total += value
+	dp.lastUpdated = c.epoch
+}
+
+// AddWithDimensions adds the value with the configured dimensions selected from attrs.
+func (c *CounterMetricBuilder) AddWithDimensions(value int64, attrs pcommon.Map) {
+	c.AddWithAttributes(value, selectAttributes(c.dimensions, attrs))
+}
+
+func (c *CounterMetricBuilder) AddDouble(value float64) {
+	c.AddDoubleWithAttributes(value, pcommon.NewMap())
+}
+
+func (c *CounterMetricBuilder) AddDoubleWithAttributes(value float64, attrs pcommon.Map) {
+	dp := c.getOrCreateDataPoint(attrs)
+	dp.doubleTotal += value
+	dp.lastUpdated = c.epoch
+}
+
+// AddDoubleWithDimensions adds the value with the configured dimensions selected from attrs.
+func (c *CounterMetricBuilder) AddDoubleWithDimensions(value float64, attrs pcommon.Map) {
+	c.AddDoubleWithAttributes(value, selectAttributes(c.dimensions, attrs))
+}
+
+// SetDimensions sets the attributes kept by AddWithDimensions and the maximum number of series.
+// Once the maximum is reached, values for new series are added to a single overflow series.
+func (c *CounterMetricBuilder) SetDimensions(dimensions []string, maxSeries int) {
+	c.dimensions = dimensions
+	c.maxSeries = maxSeries
+}
+
+func (c *CounterMetricBuilder) getOrCreateDataPoint(attrs pcommon.Map) *counterDataPoint {
 	key := pdatautil.MapHash(attrs)
 	dp, exists := c.dataPoints[key]
+	if !exists && c.maxSeries > 0 && len(c.dataPoints) >= c.maxSeries {
+		attrs = newOverflowAttributes()
+		key = pdatautil.MapHash(attrs)
+		dp, exists = c.dataPoints[key]
+	}
 	if !exists {
 		dp = newCounterDataPoint(attrs, c.startTime, c.epoch)
 		c.dataPoints[key] = dp
 	}
-	dp.total += value
-	dp.lastUpdated = c.epoch
+	return dp
 }
 
 func (c *CounterMetricBuilder) AddWithMap(value int64, attrs map[string]any) error {
@@ -272,7 +484,11 @@ func (c *CounterMetricBuilder) AppendDataPoints(scopeMetrics pmetric.ScopeMetric
 		cdp.attributes.CopyTo(dp.Attributes())
 		dp.SetStartTimestamp(cdp.startTime)
 		dp.SetTimestamp(timestamp)
-		dp.SetIntValue(cdp.total)
+		if c.isDouble {
+			dp.SetDoubleValue(cdp.doubleTotal + float64(cdp.total))
+		} else {
+			dp.SetIntValue(cdp.total)
+		}
 	}
 
 	if c.temporality == pmetric.AggregationTemporalityDelta {
@@ -283,6 +499,66 @@ func (c *CounterMetricBuilder) AppendDataPoints(scopeMetrics pmetric.ScopeMetric
 	}
 }
 
+// GaugeMetricBuilder builds a gauge of the maximum value set since the previous export, so that short peaks
+// between two exports are not lost. The gauge is exported while its value is non-zero or after it changed.
+type GaugeMetricBuilder struct {
+	name        string
+	description string
+	unit        string
+	current     int64
+	peak        int64
+	updated     bool
+}
+
+func NewGaugeMetricBuilder(name string, description string, unit string) *GaugeMetricBuilder {
+	return &GaugeMetricBuilder{
+		name:        name,
+		description: description,
+		unit:        unit,
+	}
+}
+
+func (g *GaugeMetricBuilder) Set(value int64) {
+	g.current = value
+	g.peak = max(g.peak, value)
+	g.updated = true
+}
+
+func (g *GaugeMetricBuilder) AppendDataPoints(scopeMetrics pmetric.ScopeMetrics, timestamp pcommon.Timestamp) {
+	if !g.updated && g.current == 0 {
+		return
+	}
+
+	metric := scopeMetrics.Metrics().AppendEmpty()
+	metric.SetName(g.name)
+	metric.SetDescription(g.description)
+	metric.SetUnit(g.unit)
+
+	dp := metric.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(timestamp)
+	dp.SetIntValue(g.peak)
+
+	g.peak = g.current
+	g.updated = false
+}
+
+// selectAttributes returns the attributes of attrs whose keys are listed in dimensions.
+func selectAttributes(dimensions []string, attrs pcommon.Map) pcommon.Map {
+	selected := pcommon.NewMap()
+	for _, d := range dimensions {
+		if v, ok := attrs.Get(d); ok {
+			v.CopyTo(selected.PutEmpty(d))
+		}
+	}
+	return selected
+}
+
+func newOverflowAttributes() pcommon.Map {
+	attrs := pcommon.NewMap()
+	attrs.PutBool(overflowAttributeKey, true)
+	return attrs
+}
+
 func NewFaaSInvokeDurationMetricBuilder(startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *HistogramMetricBuilder {
 	return NewHistogramMetricBuilder(
 		semconv.FaaSInvokeDurationName,
@@ -305,6 +581,28 @@ func NewFaaSInitDurationMetricBuilder(startTime pcommon.Timestamp, temporality p
 	)
 }
 
+func NewFaaSRestoreDurationMetricBuilder(startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *HistogramMetricBuilder {
+	return NewHistogramMetricBuilder(
+		RestoreDurationName,
+		RestoreDurationDescription,
+		RestoreDurationUnit,
+		DurationHistogramBounds,
+		startTime,
+		temporality,
+	)
+}
+
+func NewFaaSBilledDurationMetricBuilder(startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *HistogramMetricBuilder {
+	return NewHistogramMetricBuilder(
+		BilledDurationName,
+		BilledDurationDescription,
+		BilledDurationUnit,
+		DurationHistogramBounds,
+		startTime,
+		temporality,
+	)
+}
+
 func NewFaaSMemUsageMetricBuilder(startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *HistogramMetricBuilder {
 	return NewHistogramMetricBuilder(
 		semconv.FaaSMemUsageName,
@@ -316,6 +614,28 @@ func NewFaaSMemUsageMetricBuilder(startTime pcommon.Timestamp, temporality pmetr
 	)
 }
 
+func NewFaaSExtensionOverheadMetricBuilder(startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *HistogramMetricBuilder {
+	return NewHistogramMetricBuilder(
+		ExtensionOverheadName,
+		ExtensionOverheadDescription,
+		ExtensionOverheadUnit,
+		DurationHistogramBounds,
+		startTime,
+		temporality,
+	)
+}
+
+func NewFaaSMemUtilizationMetricBuilder(startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *HistogramMetricBuilder {
+	return NewHistogramMetricBuilder(
+		MemUtilizationName,
+		MemUtilizationDescription,
+		MemUtilizationUnit,
+		MemUtilizationHistogramBounds,
+		startTime,
+		temporality,
+	)
+}
+
 func NewFaaSColdstartsMetricBuilder(startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *CounterMetricBuilder {
 	return NewCounterMetricBuilder(
 		semconv.FaaSColdstartsName,
@@ -360,24 +680,185 @@ func NewFaaSTimeoutsMetricBuilder(startTime pcommon.Timestamp, temporality pmetr
 	)
 }
 
+func NewFaaSGBSecondsMetricBuilder(startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *CounterMetricBuilder {
+	return NewDoubleCounterMetricBuilder(
+		GBSecondsName,
+		GBSecondsDescription,
+		GBSecondsUnit,
+		true,
+		startTime,
+		temporality,
+	)
+}
+
+func NewFaaSEstimatedCostMetricBuilder(startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *CounterMetricBuilder {
+	return NewDoubleCounterMetricBuilder(
+		EstimatedCostName,
+		EstimatedCostDescription,
+		EstimatedCostUnit,
+		true,
+		startTime,
+		temporality,
+	)
+}
+
+func NewFaaSOutOfMemoryMetricBuilder(startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *CounterMetricBuilder {
+	return NewCounterMetricBuilder(
+		OutOfMemoryName,
+		OutOfMemoryDescription,
+		OutOfMemoryUnit,
+		true,
+		startTime,
+		temporality,
+	)
+}
+
+func NewFaaSLogRecordsMetricBuilder(startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *CounterMetricBuilder {
+	return NewCounterMetricBuilder(
+		LogRecordsName,
+		LogRecordsDescription,
+		LogRecordsUnit,
+		true,
+		startTime,
+		temporality,
+	)
+}
+
+func NewFaaSInFlightMetricBuilder() *GaugeMetricBuilder {
+	return NewGaugeMetricBuilder(
+		InFlightName,
+		InFlightDescription,
+		InFlightUnit,
+	)
+}
+
 type FaaSMetricBuilders struct {
-	invokeDurationMetric *HistogramMetricBuilder
-	initDurationMetric   *HistogramMetricBuilder
-	memUsageMetric       *HistogramMetricBuilder
-	coldstartsMetric     *CounterMetricBuilder
-	errorsMetric         *CounterMetricBuilder
-	invocationsMetric    *CounterMetricBuilder
-	timeoutsMetric       *CounterMetricBuilder
+	invokeDurationMetric    *HistogramMetricBuilder
+	initDurationMetric      *HistogramMetricBuilder
+	restoreDurationMetric   *HistogramMetricBuilder
+	billedDurationMetric    *HistogramMetricBuilder
+	memUsageMetric          *HistogramMetricBuilder
+	coldstartsMetric        *CounterMetricBuilder
+	errorsMetric            *CounterMetricBuilder
+	invocationsMetric       *CounterMetricBuilder
+	timeoutsMetric          *CounterMetricBuilder
+	gbSecondsMetric         *CounterMetricBuilder
+	estimatedCostMetric     *CounterMetricBuilder
+	memUtilizationMetric    *HistogramMetricBuilder
+	outOfMemoryMetric       *CounterMetricBuilder
+	extensionOverheadMetric *HistogramMetricBuilder
+	inFlightMetric          *GaugeMetricBuilder
+	logRecordsMetric        *CounterMetricBuilder
+}
+
+// dimensionsSetter is implemented by the metric builders that support configurable dimensions.
+type dimensionsSetter interface {
+	SetDimensions(dimensions []string, maxSeries int)
 }
 
 func NewFaaSMetricBuilders(startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *FaaSMetricBuilders {
 	return &FaaSMetricBuilders{
-		invokeDurationMetric: NewFaaSInvokeDurationMetricBuilder(startTime, temporality),
-		initDurationMetric:   NewFaaSInitDurationMetricBuilder(startTime, temporality),
-		memUsageMetric:       NewFaaSMemUsageMetricBuilder(startTime, temporality),
-		coldstartsMetric:     NewFaaSColdstartsMetricBuilder(startTime, temporality),
-		errorsMetric:         NewFaaSErrorsMetricBuilder(startTime, temporality),
-		invocationsMetric:    NewFaaSInvocationsMetricBuilder(startTime, temporality),
-		timeoutsMetric:       NewFaaSTimeoutsMetricBuilder(startTime, temporality),
+		invokeDurationMetric:    NewFaaSInvokeDurationMetricBuilder(startTime, temporality),
+		initDurationMetric:      NewFaaSInitDurationMetricBuilder(startTime, temporality),
+		restoreDurationMetric:   NewFaaSRestoreDurationMetricBuilder(startTime, temporality),
+		billedDurationMetric:    NewFaaSBilledDurationMetricBuilder(startTime, temporality),
+		memUsageMetric:          NewFaaSMemUsageMetricBuilder(startTime, temporality),
+		coldstartsMetric:        NewFaaSColdstartsMetricBuilder(startTime, temporality),
+		errorsMetric:            NewFaaSErrorsMetricBuilder(startTime, temporality),
+		invocationsMetric:       NewFaaSInvocationsMetricBuilder(startTime, temporality),
+		timeoutsMetric:          NewFaaSTimeoutsMetricBuilder(startTime, temporality),
+		gbSecondsMetric:         NewFaaSGBSecondsMetricBuilder(startTime, temporality),
+		estimatedCostMetric:     NewFaaSEstimatedCostMetricBuilder(startTime, temporality),
+		memUtilizationMetric:    NewFaaSMemUtilizationMetricBuilder(startTime, temporality),
+		outOfMemoryMetric:       NewFaaSOutOfMemoryMetricBuilder(startTime, temporality),
+		extensionOverheadMetric: NewFaaSExtensionOverheadMetricBuilder(startTime, temporality),
+		inFlightMetric:          NewFaaSInFlightMetricBuilder(),
+		logRecordsMetric:        NewFaaSLogRecordsMetricBuilder(startTime, temporality),
 	}
 }
+
+// byName returns the builders supporting configurable dimensions keyed by the name of the metric they build.
+func (b *FaaSMetricBuilders) byName() map[string]dimensionsSetter {
+	return map[string]dimensionsSetter{
+		b.invokeDurationMetric.name:    b.invokeDurationMetric,
+		b.initDurationMetric.name:      b.initDurationMetric,
+		b.restoreDurationMetric.name:   b.restoreDurationMetric,
+		b.billedDurationMetric.name:    b.billedDurationMetric,
+		b.memUsageMetric.name:          b.memUsageMetric,
+		b.coldstartsMetric.name:        b.coldstartsMetric,
+		b.errorsMetric.name:            b.errorsMetric,
+		b.invocationsMetric.name:       b.invocationsMetric,
+		b.timeoutsMetric.name:          b.timeoutsMetric,
+		b.gbSecondsMetric.name:         b.gbSecondsMetric,
+		b.estimatedCostMetric.name:     b.estimatedCostMetric,
+		b.memUtilizationMetric.name:    b.memUtilizationMetric,
+		b.outOfMemoryMetric.name:       b.outOfMemoryMetric,
+		b.extensionOverheadMetric.name: b.extensionOverheadMetric,
+	}
+}
+
+// configure applies the per-metric dimensions of the receiver configuration.
+func (b *FaaSMetricBuilders) configure(cfg map[string]MetricConfig) {
+	builders := b.byName()
+	for name, mc := range cfg {
+		builder, ok := builders[name]
+		if !ok {
+			continue
+		}
+		maxSeries := mc.MaxSeries
+		if maxSeries == 0 && len(mc.Dimensions) > 0 {
+			maxSeries = defaultMaxSeries
+		}
+		builder.SetDimensions(mc.Dimensions, maxSeries)
+
+		histogram, ok := builder.(*HistogramMetricBuilder)
+		if !ok {
+			continue
+		}
+		if len(mc.Buckets) > 0 {
+			histogram.SetBounds(mc.Buckets)
+		}
+		if exp := mc.Exponential; exp != nil {
+			maxSize := exp.MaxSize
+			if maxSize == 0 {
+				maxSize = DefaultExponentialHistogramMaxSize
+			}
+			maxScale := DefaultExponentialHistogramMaxScale
+			if exp.MaxScale != nil {
+				maxScale = *exp.MaxScale
+			}
+			histogram.SetExponential(maxSize, int32(maxScale))
+		}
+	}
+}
+
+// TelemetryMetricBuilders holds the builders of the metrics describing the health of the telemetry pipeline:
+// what Lambda dropped before delivery, and what the receiver received and failed to process.
+type TelemetryMetricBuilders struct {
+	droppedRecordsMetric    *CounterMetricBuilder
+	droppedBytesMetric      *CounterMetricBuilder
+	subscriptionsMetric     *CounterMetricBuilder
+	eventsMetric            *CounterMetricBuilder
+	unmarshalFailuresMetric *CounterMetricBuilder
+	consumerErrorsMetric    *CounterMetricBuilder
+}
+
+func NewTelemetryMetricBuilders(startTime pcommon.Timestamp, temporality pmetric.AggregationTemporality) *TelemetryMetricBuilders {
+	return &TelemetryMetricBuilders{
+		droppedRecordsMetric:    NewCounterMetricBuilder(LogsDroppedRecordsName, LogsDroppedRecordsDescription, LogsDroppedRecordsUnit, true, startTime, temporality),
+		droppedBytesMetric:      NewCounterMetricBuilder(LogsDroppedBytesName, LogsDroppedBytesDescription, LogsDroppedBytesUnit, true, startTime, temporality),
+		subscriptionsMetric:     NewCounterMetricBuilder(SubscriptionsName, SubscriptionsDescription, SubscriptionsUnit, true, startTime, temporality),
+		eventsMetric:            NewCounterMetricBuilder(EventsName, EventsDescription, EventsUnit, true, startTime, temporality),
+		unmarshalFailuresMetric: NewCounterMetricBuilder(UnmarshalFailuresName, UnmarshalFailuresDescription, UnmarshalFailuresUnit, true, startTime, temporality),
+		consumerErrorsMetric:    NewCounterMetricBuilder(ConsumerErrorsName, ConsumerErrorsDescription, ConsumerErrorsUnit, true, startTime, temporality),
+	}
+}
+
+func (b *TelemetryMetricBuilders) AppendDataPoints(scopeMetrics pmetric.ScopeMetrics, timestamp pcommon.Timestamp) {
+	b.droppedRecordsMetric.AppendDataPoints(scopeMetrics, timestamp)
+	b.droppedBytesMetric.AppendDataPoints(scopeMetrics, timestamp)
+	b.subscriptionsMetric.AppendDataPoints(scopeMetrics, timestamp)
+	b.eventsMetric.AppendDataPoints(scopeMetrics, timestamp)
+	b.unmarshalFailuresMetric.AppendDataPoints(scopeMetrics, timestamp)
+	b.consumerErrorsMetric.AppendDataPoints(scopeMetrics, timestamp)
+}
@@ -616,3 +616,76 @@ func TestMetricBuilder_CumulativeEpochWithAttributes(t *testing.T) {
 		assert.Equal(t, int64(25), dp.IntValue())
 	})
 }
+
+func TestMetricBuilder_Dimensions(t *testing.T) {
+	startTime := pcommon.NewTimestampFromTime(time.Now().Add(-time.Hour))
+
+	newAttrs := func(status string, version string) pcommon.Map {
+		attrs := pcommon.NewMap()
+		attrs.PutStr("aws.lambda.status", status)
+		attrs.PutStr("faas.version", version)
+		return attrs
+	}
+
+	t.Run("no dimensions drops all attributes", func(t *testing.T) {
+		builder := NewCounterMetricBuilder("test.counter", "", "1", true, startTime, pmetric.AggregationTemporalityCumulative)
+		builder.AddWithDimensions(1, newAttrs("error", "1"))
+		builder.AddWithDimensions(1, newAttrs("timeout", "2"))
+
+		scopeMetrics := pmetric.NewScopeMetrics()
+		builder.AppendDataPoints(scopeMetrics, pcommon.NewTimestampFromTime(time.Now()))
+
+		dps := scopeMetrics.Metrics().At(0).Sum().DataPoints()
+		require.Equal(t, 1, dps.Len())
+		assert.Equal(t, 0, dps.At(0).Attributes().Len())
+		assert.Equal(t, int64(2), dps.At(0).IntValue())
+	})
+
+	t.Run("configured dimensions are kept", func(t *testing.T) {
+		builder := NewHistogramMetricBuilder("test.histogram", "", "s", []float64{1.0}, startTime, pmetric.AggregationTemporalityCumulative)
+		builder.SetDimensions([]string{"aws.lambda.status"}, 0)
+		builder.RecordWithDimensions(0.5, newAttrs("error", "1"))
+		builder.RecordWithDimensions(0.5, newAttrs("error", "2"))
+		builder.RecordWithDimensions(0.5, newAttrs("success", "2"))
+
+		scopeMetrics := pmetric.NewScopeMetrics()
+		builder.AppendDataPoints(scopeMetrics, pcommon.NewTimestampFromTime(time.Now()))
+
+		dps := scopeMetrics.Metrics().At(0).Histogram().DataPoints()
+		require.Equal(t, 2, dps.Len())
+		counts := map[string]uint64{}
+		for i := 0; i < dps.Len(); i++ {
+			require.Equal(t, 1, dps.At(i).Attributes().Len())
+			status, _ := dps.At(i).Attributes().Get("aws.lambda.status")
+			counts[status.Str()] = dps.At(i).Count()
+		}
+		assert.Equal(t, map[string]uint64{"error": 2, "success": 1}, counts)
+	})
+
+	t.Run("series past the limit are aggregated into the overflow series", func(t *testing.T) {
+		builder := NewCounterMetricBuilder("test.counter", "", "1", true, startTime, pmetric.AggregationTemporalityCumulative)
+		builder.SetDimensions([]string{"faas.version"}, 2)
+		builder.AddWithDimensions(1, newAttrs("success", "1"))
+		builder.AddWithDimensions(1, newAttrs("success", "2"))
+		builder.AddWithDimensions(1, newAttrs("success", "3"))
+		builder.AddWithDimensions(1, newAttrs("success", "4"))
+		builder.AddWithDimensions(1, newAttrs("success", "1"))
+
+		scopeMetrics := pmetric.NewScopeMetrics()
+		builder.AppendDataPoints(scopeMetrics, pcommon.NewTimestampFromTime(time.Now()))
+
+		dps := scopeMetrics.Metrics().At(0).Sum().DataPoints()
+		require.Equal(t, 3, dps.Len())
+		values := map[string]int64{}
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			if _, ok := dp.Attributes().Get(overflowAttributeKey); ok {
+				values[overflowAttributeKey] = dp.IntValue()
+				continue
+			}
+			version, _ := dp.Attributes().Get("faas.version")
+			values[version.Str()] = dp.IntValue()
+		}
+		assert.Equal(t, map[string]int64{"1": 2, "2": 1, overflowAttributeKey: 2}, values)
+	})
+}
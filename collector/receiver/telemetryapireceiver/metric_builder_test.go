@@ -197,6 +197,27 @@ func TestCounterMetricBuilder_AppendDataPoint(t *testing.T) {
 	}
 }
 
+func TestDoubleCounterMetricBuilder_AppendDataPoint(t *testing.T) {
+	startTime := pcommon.NewTimestampFromTime(time.Now().Add(-time.Hour))
+	builder := NewDoubleCounterMetricBuilder("test.double_counter", "Test double counter", "1", true, startTime, pmetric.AggregationTemporalityCumulative)
+
+	metrics := pmetric.NewMetrics()
+	scopeMetrics := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	timestamp := pcommon.NewTimestampFromTime(time.Now())
+
+	builder.AddDouble(0.25)
+	builder.AddDouble(0.5)
+	builder.Add(1)
+	builder.AppendDataPoints(scopeMetrics, timestamp)
+
+	require.Equal(t, 1, scopeMetrics.Metrics().Len())
+	sum := scopeMetrics.Metrics().At(0).Sum()
+	require.Equal(t, 1, sum.DataPoints().Len())
+	dp := sum.DataPoints().At(0)
+	assert.Equal(t, pmetric.NumberDataPointValueTypeDouble, dp.ValueType())
+	assert.Equal(t, 1.75, dp.DoubleValue())
+}
+
 func TestFaaSMetricBuilderFactories(t *testing.T) {
 	startTime := pcommon.NewTimestampFromTime(time.Now())
 
@@ -220,6 +241,26 @@ func TestFaaSMetricBuilderFactories(t *testing.T) {
 		assert.Equal(t, startTime, builder.startTime)
 	})
 
+	t.Run("NewFaaSRestoreDurationMetricBuilder", func(t *testing.T) {
+		builder := NewFaaSRestoreDurationMetricBuilder(startTime, pmetric.AggregationTemporalityCumulative)
+		assert.Equal(t, RestoreDurationName, builder.name)
+		assert.Equal(t, RestoreDurationDescription, builder.description)
+		assert.Equal(t, RestoreDurationUnit, builder.unit)
+		assert.Equal(t, DurationHistogramBounds, builder.bounds)
+		assert.Equal(t, pmetric.AggregationTemporalityCumulative, builder.temporality)
+		assert.Equal(t, startTime, builder.startTime)
+	})
+
+	t.Run("NewFaaSBilledDurationMetricBuilder", func(t *testing.T) {
+		builder := NewFaaSBilledDurationMetricBuilder(startTime, pmetric.AggregationTemporalityCumulative)
+		assert.Equal(t, BilledDurationName, builder.name)
+		assert.Equal(t, BilledDurationDescription, builder.description)
+		assert.Equal(t, BilledDurationUnit, builder.unit)
+		assert.Equal(t, DurationHistogramBounds, builder.bounds)
+		assert.Equal(t, pmetric.AggregationTemporalityCumulative, builder.temporality)
+		assert.Equal(t, startTime, builder.startTime)
+	})
+
 	t.Run("NewFaaSMemUsageMetricBuilder", func(t *testing.T) {
 		builder := NewFaaSMemUsageMetricBuilder(startTime, pmetric.AggregationTemporalityCumulative)
 		assert.Equal(t, semconv.FaaSMemUsageName, builder.name)
@@ -260,6 +301,28 @@ func TestFaaSMetricBuilderFactories(t *testing.T) {
 		assert.Equal(t, startTime, builder.startTime)
 	})
 
+	t.Run("NewFaaSGBSecondsMetricBuilder", func(t *testing.T) {
+		builder := NewFaaSGBSecondsMetricBuilder(startTime, pmetric.AggregationTemporalityCumulative)
+		assert.Equal(t, GBSecondsName, builder.name)
+		assert.Equal(t, GBSecondsDescription, builder.description)
+		assert.Equal(t, GBSecondsUnit, builder.unit)
+		assert.True(t, builder.isMonotonic)
+		assert.True(t, builder.isDouble)
+		assert.Equal(t, pmetric.AggregationTemporalityCumulative, builder.temporality)
+		assert.Equal(t, startTime, builder.startTime)
+	})
+
+	t.Run("NewFaaSEstimatedCostMetricBuilder", func(t *testing.T) {
+		builder := NewFaaSEstimatedCostMetricBuilder(startTime, pmetric.AggregationTemporalityCumulative)
+		assert.Equal(t, EstimatedCostName, builder.name)
+		assert.Equal(t, EstimatedCostDescription, builder.description)
+		assert.Equal(t, EstimatedCostUnit, builder.unit)
+		assert.True(t, builder.isMonotonic)
+		assert.True(t, builder.isDouble)
+		assert.Equal(t, pmetric.AggregationTemporalityCumulative, builder.temporality)
+		assert.Equal(t, startTime, builder.startTime)
+	})
+
 	t.Run("NewFaaSTimeoutsMetricBuilder", func(t *testing.T) {
 		builder := NewFaaSTimeoutsMetricBuilder(startTime, pmetric.AggregationTemporalityCumulative)
 		assert.Equal(t, semconv.FaaSTimeoutsName, builder.name)
@@ -278,14 +341,22 @@ func TestNewFaaSMetricBuilders(t *testing.T) {
 	require.NotNil(t, builders)
 	require.NotNil(t, builders.invokeDurationMetric)
 	require.NotNil(t, builders.initDurationMetric)
+	require.NotNil(t, builders.restoreDurationMetric)
+	require.NotNil(t, builders.billedDurationMetric)
 	require.NotNil(t, builders.memUsageMetric)
 	require.NotNil(t, builders.coldstartsMetric)
 	require.NotNil(t, builders.errorsMetric)
 	require.NotNil(t, builders.invocationsMetric)
 	require.NotNil(t, builders.timeoutsMetric)
+	require.NotNil(t, builders.gbSecondsMetric)
+	require.NotNil(t, builders.estimatedCostMetric)
+	require.NotNil(t, builders.memUtilizationMetric)
+	require.NotNil(t, builders.outOfMemoryMetric)
+	require.NotNil(t, builders.extensionOverheadMetric)
 
 	assert.Equal(t, semconv.FaaSInvokeDurationName, builders.invokeDurationMetric.name)
 	assert.Equal(t, semconv.FaaSInitDurationName, builders.initDurationMetric.name)
+	assert.Equal(t, RestoreDurationName, builders.restoreDurationMetric.name)
 	assert.Equal(t, semconv.FaaSMemUsageName, builders.memUsageMetric.name)
 	assert.Equal(t, semconv.FaaSColdstartsName, builders.coldstartsMetric.name)
 	assert.Equal(t, semconv.FaaSErrorsName, builders.errorsMetric.name)
@@ -293,6 +364,76 @@ func TestNewFaaSMetricBuilders(t *testing.T) {
 	assert.Equal(t, semconv.FaaSTimeoutsName, builders.timeoutsMetric.name)
 }
 
+func TestFaaSMetricBuildersConfigure(t *testing.T) {
+	startTime := pcommon.NewTimestampFromTime(time.Now())
+	builders := NewFaaSMetricBuilders(startTime, pmetric.AggregationTemporalityCumulative)
+	maxScale := 8
+
+	builders.configure(map[string]MetricConfig{
+		semconv.FaaSInvokeDurationName: {Exponential: &ExponentialHistogramConfig{MaxScale: &maxScale}},
+		semconv.FaaSInitDurationName:   {Buckets: []float64{0.5, 1, 1.5, 2}},
+		semconv.FaaSErrorsName:         {Dimensions: []string{"error.type"}},
+		RestoreDurationName:            {Exponential: &ExponentialHistogramConfig{}},
+	})
+
+	assert.True(t, builders.invokeDurationMetric.exponential)
+	assert.Equal(t, DefaultExponentialHistogramMaxSize, builders.invokeDurationMetric.maxSize)
+	assert.Equal(t, int32(8), builders.invokeDurationMetric.maxScale)
+	assert.True(t, builders.restoreDurationMetric.exponential)
+	assert.Equal(t, int32(DefaultExponentialHistogramMaxScale), builders.restoreDurationMetric.maxScale)
+	assert.False(t, builders.initDurationMetric.exponential)
+	assert.Equal(t, []float64{0.5, 1, 1.5, 2}, builders.initDurationMetric.bounds)
+	assert.Equal(t, []string{"error.type"}, builders.errorsMetric.dimensions)
+	assert.Equal(t, defaultMaxSeries, builders.errorsMetric.maxSeries)
+	assert.False(t, builders.memUsageMetric.exponential)
+	assert.Equal(t, MemUsageHistogramBounds, builders.memUsageMetric.bounds)
+}
+
+func TestNewTelemetryMetricBuilders(t *testing.T) {
+	startTime := pcommon.NewTimestampFromTime(time.Now())
+	builders := NewTelemetryMetricBuilders(startTime, pmetric.AggregationTemporalityDelta)
+
+	builders.droppedRecordsMetric.Add(10)
+	builders.eventsMetric.Add(1)
+
+	scopeMetrics := pmetric.NewScopeMetrics()
+	builders.AppendDataPoints(scopeMetrics, pcommon.NewTimestampFromTime(time.Now()))
+
+	require.Equal(t, 2, scopeMetrics.Metrics().Len())
+	assert.Equal(t, LogsDroppedRecordsName, scopeMetrics.Metrics().At(0).Name())
+	assert.Equal(t, LogsDroppedRecordsUnit, scopeMetrics.Metrics().At(0).Unit())
+	assert.Equal(t, EventsName, scopeMetrics.Metrics().At(1).Name())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, scopeMetrics.Metrics().At(1).Sum().AggregationTemporality())
+}
+
+func TestGaugeMetricBuilder_AppendDataPoints(t *testing.T) {
+	builder := NewGaugeMetricBuilder("test.gauge", "Test gauge", "1")
+
+	scopeMetrics := pmetric.NewScopeMetrics()
+	builder.AppendDataPoints(scopeMetrics, 0)
+	require.Equal(t, 0, scopeMetrics.Metrics().Len())
+
+	builder.Set(1)
+	builder.Set(3)
+	builder.Set(2)
+	builder.AppendDataPoints(scopeMetrics, 0)
+	require.Equal(t, 1, scopeMetrics.Metrics().Len())
+	assert.Equal(t, int64(3), scopeMetrics.Metrics().At(0).Gauge().DataPoints().At(0).IntValue())
+
+	// The current value is exported while non-zero.
+	builder.AppendDataPoints(scopeMetrics, 0)
+	require.Equal(t, 2, scopeMetrics.Metrics().Len())
+	assert.Equal(t, int64(2), scopeMetrics.Metrics().At(1).Gauge().DataPoints().At(0).IntValue())
+
+	builder.Set(0)
+	builder.AppendDataPoints(scopeMetrics, 0)
+	require.Equal(t, 3, scopeMetrics.Metrics().Len())
+	assert.Equal(t, int64(2), scopeMetrics.Metrics().At(2).Gauge().DataPoints().At(0).IntValue())
+
+	builder.AppendDataPoints(scopeMetrics, 0)
+	require.Equal(t, 3, scopeMetrics.Metrics().Len())
+}
+
 func TestDefaultHistogramBounds(t *testing.T) {
 	expected := []float64{0.0, 5.0, 10.0, 25.0, 50.0, 75.0, 100.0, 250.0, 500.0, 750.0, 1000.0, 2500.0, 5000.0, 7500.0, 10000.0}
 	assert.Equal(t, expected, DefaultHistogramBounds)
@@ -0,0 +1,182 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-lambda/collector/internal/telemetryapi"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMultilineMaxLines       = 500
+	defaultMultilineFlushTimeoutMS = 1000
+)
+
+// multilinePresets are the continuation patterns of the stack traces printed by the common runtimes.
+var multilinePresets = map[string]string{
+	// Java: "\tat com.example.Handler.handleRequest(Handler.java:42)", "Caused by: ...", "\t... 12 more"
+	multilinePresetJava: `^(\s+at\s|\s+\.\.\.\s\d+\s(more|common frames omitted)|Caused by:\s|\s+Suppressed:\s)`,
+	// Python: "Traceback (most recent call last):", indented frames and code, and the final "ValueError: ..." line
+	multilinePresetPython: `^(\s+\S|Traceback \(most recent call last\):|During handling of the above exception|The above exception was the direct cause|[\w.]+(Error|Exception|Exit|Interrupt|Warning)(:|$))`,
+	// Node.js: "    at handler (/var/task/index.js:3:9)"
+	multilinePresetNodejs: `^\s+at\s`,
+}
+
+// multilineAggregator joins consecutive plain text function log lines into a single log record, such as
+// the lines of a stack trace. The record being joined is held until a line starts a new record, the
+// maximum number of lines is reached, the invocation ends or no line is joined for the flush timeout,
+// possibly across Telemetry API requests.
+type multilineAggregator struct {
+	start        *regexp.Regexp
+	continuation *regexp.Regexp
+	maxLines     int
+	flushTimeout time.Duration
+
+	pending    plog.LogRecord
+	hasPending bool
+	lines      []string // continuation lines of the pending record
+
+	updates   uint64      // number of lines begun or joined, identifies the state of the pending record
+	scheduled uint64      // updates when the flush timer was started
+	timer     *time.Timer // completes the pending record after the flush timeout
+}
+
+func newMultilineAggregator(cfg *MultilineConfig) (*multilineAggregator, error) {
+	m := &multilineAggregator{maxLines: cfg.MaxLines, flushTimeout: time.Duration(cfg.FlushTimeoutMS) * time.Millisecond}
+	if m.maxLines == 0 {
+		m.maxLines = defaultMultilineMaxLines
+	}
+	if m.flushTimeout == 0 {
+		m.flushTimeout = defaultMultilineFlushTimeoutMS * time.Millisecond
+	}
+
+	continuation := cfg.ContinuationPattern
+	if cfg.Preset != "" {
+		continuation = multilinePresets[cfg.Preset]
+	}
+	var err error
+	if continuation != "" {
+		if m.continuation, err = regexp.Compile(continuation); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.StartPattern != "" {
+		if m.start, err = regexp.Compile(cfg.StartPattern); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// isContinuation reports whether the line continues the previous one. The continuation pattern takes
+// precedence; with only a start pattern, every line not matching it is a continuation.
+func (m *multilineAggregator) isContinuation(line string) bool {
+	if m.continuation != nil {
+		return m.continuation.MatchString(line)
+	}
+	return m.start != nil && !m.start.MatchString(line)
+}
+
+// join appends the line to the pending record if it continues it, reporting whether it did.
+func (m *multilineAggregator) join(line string) bool {
+	if !m.hasPending || len(m.lines)+1 >= m.maxLines || !m.isContinuation(line) {
+		return false
+	}
+	m.lines = append(m.lines, line)
+	m.updates++
+	return true
+}
+
+// begin flushes the pending record to scopeLog and returns a new pending record.
+func (m *multilineAggregator) begin(scopeLog plog.ScopeLogs) plog.LogRecord {
+	m.flush(scopeLog)
+	m.pending = plog.NewLogRecord()
+	m.hasPending = true
+	m.updates++
+	return m.pending
+}
+
+// flush appends the pending record with its continuation lines to scopeLog.
+func (m *multilineAggregator) flush(scopeLog plog.ScopeLogs) {
+	if !m.hasPending {
+		return
+	}
+	if len(m.lines) > 0 {
+		m.pending.Body().SetStr(m.pending.Body().Str() + "\n" + strings.Join(m.lines, "\n"))
+	}
+	m.pending.MoveTo(scopeLog.LogRecords().AppendEmpty())
+	m.hasPending = false
+	m.lines = nil
+}
+
+// scheduleMultilineFlush starts the flush timer of the record being joined, if it changed since the timer was
+// started, so that the last record of an invocation is not held when the end of the invocation is not received,
+// e.g. when platform events are not subscribed to. The caller must hold r.mu.
+func (r *telemetryAPIReceiver) scheduleMultilineFlush() {
+	m := r.multiline
+	if m == nil || !m.hasPending || m.scheduled == m.updates {
+		return
+	}
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+	updates := m.updates
+	m.scheduled = updates
+	m.timer = time.AfterFunc(m.flushTimeout, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		// A line was joined meanwhile, the record is flushed by the next timer.
+		if m.updates != updates {
+			return
+		}
+		if err := r.flushMultilineLogsLocked(context.Background()); err != nil {
+			r.logger.Error("error flushing logs", zap.Error(err))
+		}
+	})
+}
+
+// flushMultilineLogs sends the log record being joined, if any, to the next consumer.
+func (r *telemetryAPIReceiver) flushMultilineLogs(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.multiline != nil && r.multiline.timer != nil {
+		r.multiline.timer.Stop()
+	}
+	return r.flushMultilineLogsLocked(ctx)
+}
+
+// flushMultilineLogsLocked sends the log record being joined, if any, to the next consumer. The caller must
+// hold r.mu.
+func (r *telemetryAPIReceiver) flushMultilineLogsLocked(ctx context.Context) error {
+	if r.multiline == nil || !r.multiline.hasPending {
+		return nil
+	}
+	log := r.newSourceLogs()
+	r.multiline.flush(log.scopeLogs(telemetryapi.Function))
+	r.dropLogSources(log.logs)
+
+	if r.nextLogs == nil || log.logs.LogRecordCount() == 0 {
+		return nil
+	}
+	return r.nextLogs.ConsumeLogs(ctx, log.logs)
+}
@@ -0,0 +1,171 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func TestMultilinePresets(t *testing.T) {
+	testCases := []struct {
+		preset       string
+		continuation []string
+		start        []string
+	}{
+		{
+			preset: multilinePresetJava,
+			continuation: []string{
+				"\tat com.example.Handler.handleRequest(Handler.java:42)",
+				"Caused by: java.io.IOException: closed",
+				"\t... 12 more",
+				"\tSuppressed: java.lang.IllegalStateException",
+			},
+			start: []string{"java.lang.RuntimeException: failed", "Processing order 42"},
+		},
+		{
+			preset: multilinePresetPython,
+			continuation: []string{
+				"Traceback (most recent call last):",
+				`  File "/var/task/app.py", line 3, in handler`,
+				"    raise ValueError(\"bad input\")",
+				"ValueError: bad input",
+				"During handling of the above exception, another exception occurred:",
+			},
+			start: []string{"Processing order 42", "[ERROR] something failed"},
+		},
+		{
+			preset:       multilinePresetNodejs,
+			continuation: []string{"    at handler (/var/task/index.js:3:9)"},
+			start:        []string{"Error: failed", "Processing order 42"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.preset, func(t *testing.T) {
+			m, err := newMultilineAggregator(&MultilineConfig{Preset: tc.preset})
+			require.NoError(t, err)
+			for _, line := range tc.continuation {
+				require.True(t, m.isContinuation(line), line)
+			}
+			for _, line := range tc.start {
+				require.False(t, m.isContinuation(line), line)
+			}
+		})
+	}
+}
+
+func TestMultilineStartPattern(t *testing.T) {
+	m, err := newMultilineAggregator(&MultilineConfig{StartPattern: `^\d{4}-\d{2}-\d{2}`})
+	require.NoError(t, err)
+	require.False(t, m.isContinuation("2024-01-02 15:04:05 starting"))
+	require.True(t, m.isContinuation("details of the previous line"))
+
+	m, err = newMultilineAggregator(&MultilineConfig{StartPattern: `^START`, ContinuationPattern: `^\s`})
+	require.NoError(t, err)
+	require.True(t, m.isContinuation("  indented"))
+	require.False(t, m.isContinuation("not indented"))
+}
+
+func TestCreateLogsMultiline(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(
+		&Config{TextLogFormat: textLogFormatNone, Multiline: &MultilineConfig{Preset: multilinePresetJava, MaxLines: 3}},
+		receivertest.NewNopSettings(Type),
+	)
+	require.NoError(t, err)
+
+	logs, err := r.createLogs([]event{
+		{Time: "2024-01-02T15:04:05.000Z", Type: "function", Record: "Processing order 42"},
+		{Time: "2024-01-02T15:04:05.001Z", Type: "function", Record: "java.lang.RuntimeException: failed"},
+		{Time: "2024-01-02T15:04:05.002Z", Type: "function", Record: "\tat com.example.Handler.handleRequest(Handler.java:42)"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Processing order 42"}, logBodies(logs))
+
+	// The stack trace continues in the next request, is split at max_lines and flushed when the runtime is done.
+	logs, err = r.createLogs([]event{
+		{Time: "2024-01-02T15:04:05.003Z", Type: "function", Record: "\tat com.example.Main.run(Main.java:7)"},
+		{Time: "2024-01-02T15:04:05.004Z", Type: "function", Record: "\tat com.example.Main.main(Main.java:3)"},
+		{Time: "2024-01-02T15:04:05.005Z", Type: "platform.runtimeDone", Record: map[string]any{"requestId": "test-id", "status": "error"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"java.lang.RuntimeException: failed\n\tat com.example.Handler.handleRequest(Handler.java:42)\n\tat com.example.Main.run(Main.java:7)",
+		"\tat com.example.Main.main(Main.java:3)",
+		"",
+	}, logBodies(logs))
+	require.False(t, r.multiline.hasPending)
+}
+
+func TestShutdownFlushesMultilineLogs(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(
+		&Config{Multiline: &MultilineConfig{Preset: multilinePresetNodejs}},
+		receivertest.NewNopSettings(Type),
+	)
+	require.NoError(t, err)
+	sink := &consumertest.LogsSink{}
+	r.registerLogsConsumer(sink)
+
+	_, err = r.createLogs([]event{
+		{Time: "2024-01-02T15:04:05.000Z", Type: "function", Record: "Error: failed"},
+		{Time: "2024-01-02T15:04:05.001Z", Type: "function", Record: "    at handler (/var/task/index.js:3:9)"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, r.Shutdown(context.Background()))
+	require.Len(t, sink.AllLogs(), 1)
+	require.Equal(t, []string{"Error: failed\n    at handler (/var/task/index.js:3:9)"}, logBodies(sink.AllLogs()[0]))
+}
+
+func logBodies(logs plog.Logs) []string {
+	var bodies []string
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		records := logs.ResourceLogs().At(i).ScopeLogs().At(0).LogRecords()
+		for j := 0; j < records.Len(); j++ {
+			bodies = append(bodies, records.At(j).Body().AsString())
+		}
+	}
+	return bodies
+}
+
+func TestMultilineFlushTimeout(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(
+		&Config{Types: []string{function}, Multiline: &MultilineConfig{Preset: multilinePresetNodejs, FlushTimeoutMS: 50}},
+		receivertest.NewNopSettings(Type),
+	)
+	require.NoError(t, err)
+	sink := &consumertest.LogsSink{}
+	r.registerLogsConsumer(sink)
+
+	require.Equal(t, 200, postBody(r, `[
+		{"time":"2024-01-02T15:04:05.000Z","type":"function","record":"Error: failed"},
+		{"time":"2024-01-02T15:04:05.001Z","type":"function","record":"    at handler (/var/task/index.js:3:9)"}
+	]`))
+	require.Equal(t, 0, sink.LogRecordCount())
+
+	// Without the end of the invocation, the record is completed once no line is joined for the flush timeout.
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		require.Equal(c, 1, sink.LogRecordCount())
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"Error: failed\n    at handler (/var/task/index.js:3:9)"}, logBodies(sink.AllLogs()[0]))
+	require.NoError(t, r.Shutdown(context.Background()))
+	require.Equal(t, 1, sink.LogRecordCount())
+}
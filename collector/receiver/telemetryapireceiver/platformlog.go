@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"github.com/open-telemetry/opentelemetry-lambda/collector/internal/telemetryapi"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+const (
+	attributeDurationMs        = "aws.lambda.duration_ms"
+	attributeRestoreDurationMs = "aws.lambda.restore_duration_ms"
+	attributeProducedBytes     = "aws.lambda.produced_bytes"
+	attributePhase             = "aws.lambda.phase"
+)
+
+// platformMetricAttributes maps the metrics of platform events to log record attributes. The attributes
+// shared with the invocation span have the same keys and types.
+var platformMetricAttributes = map[string]string{
+	string(telemetryapi.MetricDurationMs):       attributeDurationMs,
+	string(telemetryapi.MetricBilledDurationMs): attributeBilledDurationMs,
+	string(telemetryapi.MetricMemorySizeMB):     attributeMemorySizeMB,
+	string(telemetryapi.MetricMaxMemoryUsedMB):  attributeMaxMemoryUsedMB,
+	string(telemetryapi.MetricInitDurationMs):   attributeInitDurationMs,
+	"restoreDurationMs":                         attributeRestoreDurationMs,
+	"producedBytes":                             attributeProducedBytes,
+}
+
+// platformFieldAttributes maps the string fields of platform events to log record attributes.
+var platformFieldAttributes = map[string]string{
+	"status":             dimensionStatus,
+	"errorType":          string(semconv.ErrorTypeKey),
+	"initializationType": dimensionInitializationType,
+	"phase":              attributePhase,
+}
+
+// putPlatformLogAttributes adds the fields of a platform event to its log record as typed attributes,
+// so that they can be queried without parsing the formatted message. It reports whether any field was added.
+func putPlatformLogAttributes(attrs pcommon.Map, record map[string]any) bool {
+	added := false
+	if metrics, ok := record["metrics"].(map[string]any); ok {
+		for field, key := range platformMetricAttributes {
+			if value, ok := metrics[field].(float64); ok {
+				attrs.PutDouble(key, value)
+				added = true
+			}
+		}
+	}
+	for field, key := range platformFieldAttributes {
+		if value, ok := record[field].(string); ok && value != "" {
+			attrs.PutStr(key, value)
+			added = true
+		}
+	}
+	return added
+}
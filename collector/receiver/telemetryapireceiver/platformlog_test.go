@@ -0,0 +1,135 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+func TestCreateLogsStructuredPlatformLogs(t *testing.T) {
+	events := []event{
+		{
+			Time: "2022-10-12T00:03:50.000Z",
+			Type: "platform.initReport",
+			Record: map[string]any{
+				"initializationType": "on-demand",
+				"phase":              "init",
+				"status":             "success",
+				"metrics":            map[string]any{"durationMs": 125.33},
+			},
+		},
+		{
+			Time: "2022-10-12T00:03:51.000Z",
+			Type: "platform.report",
+			Record: map[string]any{
+				"requestId": "test-id",
+				"status":    "timeout",
+				"errorType": "Sandbox.Timedout",
+				"metrics": map[string]any{
+					"durationMs":       3000.12,
+					"billedDurationMs": 3000.0,
+					"memorySizeMB":     128.0,
+					"maxMemoryUsedMB":  64.0,
+					"initDurationMs":   125.33,
+				},
+			},
+		},
+	}
+
+	structured := []map[string]any{
+		{
+			"type":                           "platform.initReport",
+			"aws.lambda.initialization_type": "on-demand",
+			"aws.lambda.phase":               "init",
+			"aws.lambda.status":              "success",
+			"aws.lambda.duration_ms":         125.33,
+		},
+		{
+			"type":                              "platform.report",
+			string(semconv.FaaSInvocationIDKey): "test-id",
+			"aws.lambda.status":                 "timeout",
+			"error.type":                        "Sandbox.Timedout",
+			"aws.lambda.duration_ms":            3000.12,
+			"aws.lambda.billed_duration_ms":     3000.0,
+			"aws.lambda.memory_size_mb":         128.0,
+			"aws.lambda.max_memory_used_mb":     64.0,
+			"aws.lambda.init_duration_ms":       125.33,
+		},
+	}
+
+	testCases := []struct {
+		desc         string
+		format       string
+		expected     []map[string]any
+		expectedBody bool
+	}{
+		{
+			desc:   "text",
+			format: platformLogFormatText,
+			expected: []map[string]any{
+				{"type": "platform.initReport"},
+				{"type": "platform.report", string(semconv.FaaSInvocationIDKey): "test-id"},
+			},
+			expectedBody: true,
+		},
+		{
+			desc:         "structured",
+			format:       platformLogFormatStructured,
+			expected:     structured,
+			expectedBody: true,
+		},
+		{
+			desc:     "structured without body",
+			format:   platformLogFormatStructuredNoBody,
+			expected: structured,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := newTelemetryAPIReceiver(&Config{LogReport: true, PlatformLogFormat: tc.format}, receivertest.NewNopSettings(Type))
+			require.NoError(t, err)
+
+			logs, err := r.createLogs(events)
+			require.NoError(t, err)
+
+			records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+			require.Equal(t, len(tc.expected), records.Len())
+			for i, expected := range tc.expected {
+				require.Equal(t, expected, records.At(i).Attributes().AsRaw())
+				require.Equal(t, tc.expectedBody, records.At(i).Body().Str() != "")
+			}
+		})
+	}
+}
+
+func TestCreateLogsStructuredNoBodyWithoutAttributes(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{PlatformLogFormat: platformLogFormatStructuredNoBody}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	// platform.extension has no typed attributes, so its record keeps the formatted message.
+	logs, err := r.createLogs([]event{{
+		Time:   "2022-10-12T00:03:50.000Z",
+		Type:   "platform.extension",
+		Record: map[string]any{"name": "my-extension", "state": "Ready", "events": []any{"INVOKE"}},
+	}})
+	require.NoError(t, err)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, records.Len())
+	require.NotEmpty(t, records.At(0).Body().Str())
+}
@@ -24,6 +24,8 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -64,8 +66,22 @@ const (
 	platformLogsDroppedLogFmt           = "LOGS_DROPPED DroppedRecords: %.0f DroppedBytes: %.0f Reason: %s"
 )
 
+const (
+	dimensionStatus             = "aws.lambda.status"
+	dimensionInitializationType = "aws.lambda.initialization_type"
+)
+
+// supportedDimensions lists the attributes that can be configured as dimensions of the FaaS metrics.
+var supportedDimensions = []string{
+	dimensionStatus,
+	string(semconv.ErrorTypeKey),
+	dimensionInitializationType,
+	string(semconv.FaaSVersionKey),
+	string(semconv.FaaSColdstartKey),
+}
+
 type telemetryAPIReceiver struct {
-	httpServer              *http.Server
+	broker                  *subscriptionBroker
 	logger                  *zap.Logger
 	queue                   *queue.Queue // queue is a synchronous queue and is used to put the received log events to be dispatched later
 	mu                      sync.Mutex
@@ -74,16 +90,47 @@ type telemetryAPIReceiver struct {
 	nextLogs                consumer.Logs
 	lastPlatformStartTime   string
 	lastPlatformEndTime     string
+	lastRestoreStartTime    string
+	lastInitTraceID         pcommon.TraceID
+	lastInitSpanID          pcommon.SpanID
 	extensionID             string
 	port                    int
+	protocol                string
 	types                   []telemetryapi.EventType
+	buffering               telemetryapi.BufferingCfg
+	schemaVersion           telemetryapi.SchemaVersion
 	resource                pcommon.Resource
 	faasFunctionVersion     string
 	faasName                string
 	faaSMetricBuilders      *FaaSMetricBuilders
+	telemetryMetrics        *TelemetryMetricBuilders
+	oomMetrics              outOfMemoryDetector
+	oomLogs                 outOfMemoryDetector
+	runtimeDurations        map[string]float64      // platform.runtimeDone durations in ms by request ID, until reported
+	inFlight                map[string]struct{}     // request IDs of the invocations between platform.start and platform.runtimeDone
+	logTraceContexts        map[string]traceContext // trace contexts of the invocations by request ID, until reported
+	textLogParser           textLogParser           // parser of plain text function logs, nil when disabled
+	multiline               *multilineAggregator    // joins multiline function logs, nil when disabled
 	currentFaasInvocationID string
+	invocations             map[string]*invocation
+	expiring                map[string]struct{} // request IDs tracked at lastExpiry, forgotten at the next expiry
+	lastExpiry              time.Time
 	lambdaInitType          lambdalifecycle.InitType
+	deterministicIDs        bool
+	pendingColdstart        bool
+	prices                  *ArchitecturePrices
 	logReport               bool
+	structuredPlatformLogs  bool // add the fields of platform events to their log records as attributes
+	platformLogBody         bool // keep the formatted message of structured platform log records
+	emf                     bool // extract metrics from EMF function logs
+	emfDropLogs             bool
+	emfMetrics              pmetric.ScopeMetricsSlice // extracted EMF metrics, one scope per namespace
+	logSeverityMetrics      bool                      // count function and extension log records by severity
+	logSources              []string                  // sources whose log records are emitted, all when empty
+	extensions              []string                  // names of the extensions reported by platform.extension events
+	backpressure            bool                      // answer failures with non-2xx statuses so that Lambda retries
+	maxRetries              int
+	rejected                *rejectedRequest // last request answered with a failure status
 	exportInterval          time.Duration
 	stopCh                  chan struct{}
 	wg                      sync.WaitGroup
@@ -105,52 +152,40 @@ func (r *telemetryAPIReceiver) Start(ctx context.Context, host component.Host) e
 		return fmt.Errorf("no telemetry event types provided")
 	}
 
-	listener, address, err := r.bindListener()
-	if err != nil {
-		return fmt.Errorf("failed to find available port: %w", err)
-	}
-	r.logger.Info("Starting telemetry API listener", zap.String("address", address))
-
-	mux := http.NewServeMux()
-	mux.HandleFunc("/", r.httpHandler)
-	r.httpServer = &http.Server{Addr: address, Handler: mux}
-	go func() {
-		err := r.httpServer.Serve(listener)
-		if !errors.Is(err, http.ErrServerClosed) {
-			r.logger.Error("Unexpected stop on HTTP Server", zap.Error(err))
-		} else {
-			r.logger.Info("HTTP server closed", zap.Error(err))
-		}
-	}()
-
 	if r.exportInterval > 0 {
 		r.wg.Add(1)
 		go r.startMetricsExporter()
 	}
 
-	telemetryClient := telemetryapi.NewClient(r.logger)
-	if _, err := telemetryClient.Subscribe(ctx, r.types, r.extensionID, fmt.Sprintf("http://%s/", address)); err != nil {
+	if err := r.broker.register(ctx, r); err != nil {
 		r.logger.Error("Failed to subscribe to telemetry", zap.Error(err))
 		_ = r.Shutdown(ctx)
 		return err
 	}
-	r.logger.Info("Successfully subscribed to telemetry", zap.String("address", address))
 	return nil
 }
 
-func (r *telemetryAPIReceiver) Shutdown(ctx context.Context) error {
-	close(r.stopCh)
-	r.wg.Wait()
+// subscriptionSettings returns the settings of the Telemetry API subscription the receiver is configured with.
+func (r *telemetryAPIReceiver) subscriptionSettings() subscriptionSettings {
+	return subscriptionSettings{
+		port:          r.port,
+		protocol:      r.protocol,
+		buffering:     r.buffering,
+		schemaVersion: r.schemaVersion,
+	}
+}
 
+func (r *telemetryAPIReceiver) Shutdown(ctx context.Context) error {
 	var errs []error
 
-	if r.httpServer != nil {
-		if err := r.httpServer.Shutdown(ctx); err != nil {
-			r.logger.Error("error shutting down http server", zap.Error(err))
-			errs = append(errs, err)
-		}
+	if err := r.broker.unregister(ctx, r); err != nil {
+		r.logger.Error("error shutting down telemetry API listener", zap.Error(err))
+		errs = append(errs, err)
 	}
 
+	close(r.stopCh)
+	r.wg.Wait()
+
 	if r.exportInterval > 0 {
 		if err := r.flushMetrics(ctx); err != nil {
 			r.logger.Error("error while flushing metrics", zap.Error(err))
@@ -158,6 +193,11 @@ func (r *telemetryAPIReceiver) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	if err := r.flushMultilineLogs(ctx); err != nil {
+		r.logger.Error("error while flushing logs", zap.Error(err))
+		errs = append(errs, err)
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -200,16 +240,58 @@ func (r *telemetryAPIReceiver) flushMetricsLocked(ctx context.Context) error {
 	r.faaSMetricBuilders.errorsMetric.AppendDataPoints(scopeMetric, ts)
 	r.faaSMetricBuilders.timeoutsMetric.AppendDataPoints(scopeMetric, ts)
 	r.faaSMetricBuilders.initDurationMetric.AppendDataPoints(scopeMetric, ts)
+	r.faaSMetricBuilders.restoreDurationMetric.AppendDataPoints(scopeMetric, ts)
+	r.faaSMetricBuilders.billedDurationMetric.AppendDataPoints(scopeMetric, ts)
 	r.faaSMetricBuilders.memUsageMetric.AppendDataPoints(scopeMetric, ts)
 	r.faaSMetricBuilders.invocationsMetric.AppendDataPoints(scopeMetric, ts)
 	r.faaSMetricBuilders.invokeDurationMetric.AppendDataPoints(scopeMetric, ts)
+	r.faaSMetricBuilders.gbSecondsMetric.AppendDataPoints(scopeMetric, ts)
+	r.faaSMetricBuilders.estimatedCostMetric.AppendDataPoints(scopeMetric, ts)
+	r.faaSMetricBuilders.memUtilizationMetric.AppendDataPoints(scopeMetric, ts)
+	r.faaSMetricBuilders.outOfMemoryMetric.AppendDataPoints(scopeMetric, ts)
+	r.faaSMetricBuilders.extensionOverheadMetric.AppendDataPoints(scopeMetric, ts)
+	r.faaSMetricBuilders.inFlightMetric.AppendDataPoints(scopeMetric, ts)
+	r.faaSMetricBuilders.logRecordsMetric.AppendDataPoints(scopeMetric, ts)
+	r.telemetryMetrics.AppendDataPoints(scopeMetric, ts)
+	r.emfMetrics.MoveAndAppendTo(resourceMetric.ScopeMetrics())
 
 	if metric.MetricCount() > 0 && r.nextMetrics != nil {
-		return r.nextMetrics.ConsumeMetrics(ctx, metric)
+		if err := r.nextMetrics.ConsumeMetrics(ctx, metric); err != nil {
+			r.recordConsumerError("metrics")
+			return err
+		}
 	}
 	return nil
 }
 
+// recordLogRecords counts the function and extension log records by event type and severity number.
+func (r *telemetryAPIReceiver) recordLogRecords(logs plog.Logs) {
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		scopeLogs := logs.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < scopeLogs.Len(); j++ {
+			records := scopeLogs.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				record := records.At(k)
+				eventType, _ := record.Attributes().Get("type")
+				if eventType.Str() != function && eventType.Str() != extension {
+					continue
+				}
+				attrs := pcommon.NewMap()
+				attrs.PutStr(attributeEventType, eventType.Str())
+				attrs.PutInt(attributeSeverityNumber, int64(record.SeverityNumber()))
+				r.faaSMetricBuilders.logRecordsMetric.AddWithAttributes(1, attrs)
+			}
+		}
+	}
+}
+
+// recordConsumerError counts an error returned by the next consumer of the signal.
+func (r *telemetryAPIReceiver) recordConsumerError(signal string) {
+	attrs := pcommon.NewMap()
+	attrs.PutStr(attributeSignal, signal)
+	r.telemetryMetrics.consumerErrorsMetric.AddWithAttributes(1, attrs)
+}
+
 func newSpanID() pcommon.SpanID {
 	sid := pcommon.SpanID{}
 	_, _ = crand.Read(sid[:])
@@ -222,9 +304,7 @@ func newTraceID() pcommon.TraceID {
 	return tid
 }
 
-// httpHandler handles the requests coming from the Telemetry API.
-// Everytime Telemetry API sends events, this function will read them from the response body
-// and put into a synchronous queue to be dispatched later.
+// httpHandler handles a request of the Telemetry API carrying the receiver's events.
 // Logging or printing besides the error cases below is not recommended if you have subscribed to
 // receive extension logs. Otherwise, logging here will cause Telemetry API to send new logs for
 // the printed lines which may create an infinite loop.
@@ -232,20 +312,131 @@ func (r *telemetryAPIReceiver) httpHandler(w http.ResponseWriter, req *http.Requ
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
 		r.logger.Error("error reading body", zap.Error(err))
+		w.WriteHeader(r.rejectUnreadable())
 		return
 	}
+	w.WriteHeader(r.handleBody(body))
+}
+
+// rejectUnreadable returns the status answering a request whose body could not be read.
+func (r *telemetryAPIReceiver) rejectUnreadable() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Unreadable requests share a hash of 0, their retries are bounded together.
+	return r.reject(newRejectedRequest(0), http.StatusInternalServerError)
+}
+
+// handleBody processes the JSON array of events of a Telemetry API request, and returns the status to answer it with.
+func (r *telemetryAPIReceiver) handleBody(body []byte) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hash := hashBody(body)
+	if r.rejected != nil && r.rejected.hash == hash && r.rejected.hasTelemetry() {
+		// Lambda delivers a rejected request again, only the consumers that failed need to be retried.
+		return r.retryRejected(context.Background())
+	}
+	failed := newRejectedRequest(hash)
 
 	var slice []event
 	if err := json.Unmarshal(body, &slice); err != nil {
 		r.logger.Error("error unmarshalling body", zap.Error(err))
+		r.telemetryMetrics.unmarshalFailuresMetric.Add(1)
+		return r.reject(failed, http.StatusBadRequest)
+	}
+
+	r.markStateEvents(slice)
+	r.processEvents(slice, failed)
+	r.logger.Debug("logEvents received", zap.Int("count", len(slice)), zap.Int64("queue_length", r.queue.Len()))
+
+	if failed.hasTelemetry() {
+		return r.reject(failed, http.StatusServiceUnavailable)
+	}
+	r.rejected = nil
+	return http.StatusOK
+}
+
+// markStateEvents marks the events of the types the receiver is not subscribed to, which the broker only hands it
+// to track the state of the invocations with, see tracksState. A receiver without types is not registered with
+// the broker.
+func (r *telemetryAPIReceiver) markStateEvents(slice []event) {
+	if len(r.types) == 0 {
 		return
 	}
+	for i := range slice {
+		slice[i].stateOnly = !slices.Contains(r.types, eventSource(slice[i].Type))
+	}
+}
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
+// trackStateEvent keeps the state of the invocations and extensions from an event the receiver does not emit
+// telemetry for.
+func (r *telemetryAPIReceiver) trackStateEvent(el event) {
+	record, ok := el.Record.(map[string]any)
+	if !ok {
+		return
+	}
+	r.updateInFlight(el.Type, record)
+	r.recordInvocationException(el, record)
+	switch el.Type {
+	case string(telemetryapi.PlatformExtension):
+		r.trackExtension(record)
+	case string(telemetryapi.PlatformStart):
+		if version, _ := record["version"].(string); version != "" {
+			r.faasFunctionVersion = version
+		}
+		r.startInvocation(el.Time, record)
+	case string(telemetryapi.PlatformRuntimeDone):
+		r.finishInvocation(el.Time, record)
+	case string(telemetryapi.PlatformReport):
+		// The invocation is forgotten once reported, its span is emitted by the receivers subscribed to platform events.
+		_, _ = r.reportInvocation(record)
+	}
+}
 
-	for _, el := range slice {
+// trackLogContext keeps the invocation logs are attributed to, and its trace context, from an event the receiver
+// does not emit a log record for.
+func (r *telemetryAPIReceiver) trackLogContext(el event) {
+	record, ok := el.Record.(map[string]any)
+	if !ok {
+		return
+	}
+	requestID := r.getRecordRequestId(record)
+	switch el.Type {
+	case string(telemetryapi.PlatformStart):
+		if requestID != "" {
+			r.updateCurrentRequestId(requestID)
+		}
+	case string(telemetryapi.PlatformRuntimeDone):
+		r.updateCurrentRequestId("")
+	}
+	r.rememberLogTraceContext(el.Type, record, requestID)
+	r.forgetLogTraceContext(el)
+}
+
+// processEvents turns a batch of events received from the Telemetry API into telemetry and sends it to the next
+// consumers. The telemetry the consumers failed to accept is added to failed. The caller must hold r.mu.
+func (r *telemetryAPIReceiver) processEvents(slice []event, failed *rejectedRequest) {
+	r.updateResourceID()
+	r.expireInvocations(time.Now())
+	for i, el := range slice {
+		if el.stateOnly {
+			r.trackStateEvent(el)
+			continue
+		}
 		r.logger.Debug(fmt.Sprintf("Event: %s", el.Type), zap.Any("event", el))
+		if record, ok := el.Record.(map[string]any); ok {
+			r.trackInFlight(el.Type, record)
+			r.recordInvocationException(el, record)
+		}
+		// With Lambda Managed Instances several invocations run at once, so events without a request ID can only
+		// be attributed while a single invocation is in flight.
+		if r.lambdaInitType == lambdalifecycle.LambdaManagedInstances {
+			slice[i].requestID = r.inFlightRequestID()
+		}
+		eventAttrs := pcommon.NewMap()
+		eventAttrs.PutStr(attributeEventType, el.Type)
+		r.telemetryMetrics.eventsMetric.AddWithAttributes(1, eventAttrs)
 		switch el.Type {
 		// Function initialization started.
 		case string(telemetryapi.PlatformInitStart):
@@ -259,6 +450,9 @@ func (r *telemetryAPIReceiver) httpHandler(w http.ResponseWriter, req *http.Requ
 				if functionName != "" {
 					r.faasName = functionName
 				}
+				if functionVersion, _ := record["functionVersion"].(string); functionVersion != "" {
+					r.faasFunctionVersion = functionVersion
+				}
 			}
 		// Function initialization completed.
 		case string(telemetryapi.PlatformInitRuntimeDone):
@@ -270,13 +464,17 @@ func (r *telemetryAPIReceiver) httpHandler(w http.ResponseWriter, req *http.Requ
 			if len(r.lastPlatformStartTime) > 0 && len(r.lastPlatformEndTime) > 0 {
 				if record, ok := el.Record.(map[string]any); ok {
 					if td, err := r.createPlatformInitSpan(record, r.lastPlatformStartTime, r.lastPlatformEndTime); err == nil {
-						if r.nextTraces != nil {
-							err := r.nextTraces.ConsumeTraces(context.Background(), td)
-							if err == nil {
+						if td.SpanCount() == 0 {
+							// The trace is not sampled, so no span will cover this init.
+							r.lastPlatformEndTime = ""
+							r.lastPlatformStartTime = ""
+						} else if r.nextTraces != nil {
+							initSpan := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+							r.lastInitTraceID = initSpan.TraceID()
+							r.lastInitSpanID = initSpan.SpanID()
+							if err := r.consumeTraces(context.Background(), td, failed); err == nil {
 								r.lastPlatformEndTime = ""
 								r.lastPlatformStartTime = ""
-							} else {
-								r.logger.Error("error receiving traces", zap.Error(err))
 							}
 						}
 					}
@@ -287,27 +485,75 @@ func (r *telemetryAPIReceiver) httpHandler(w http.ResponseWriter, req *http.Requ
 				r.lastPlatformEndTime = el.Time
 				r.logger.Info(fmt.Sprintf("Init end: %s", r.lastPlatformEndTime), zap.Any("event", el))
 			}
-		}
-		// TODO: add support for additional events, see https://docs.aws.amazon.com/lambda/latest/dg/telemetry-api.html
-		// A report of function initialization.
-		// case "platform.initReport":
+
+			if record, ok := el.Record.(map[string]any); ok && r.nextTraces != nil {
+				if td := r.createPlatformInitReportSpans(record); td.SpanCount() > 0 {
+					_ = r.consumeTraces(context.Background(), td, failed)
+				}
+			}
+		// Runtime restore started.
+		case string(telemetryapi.PlatformRestoreStart):
+			if el.Time != "" {
+				r.lastRestoreStartTime = el.Time
+			}
+		// Runtime restore completed.
+		case string(telemetryapi.PlatformRestoreRuntimeDone):
+			if r.lastRestoreStartTime != "" && el.Time != "" {
+				if record, ok := el.Record.(map[string]any); ok {
+					if td, err := r.createPlatformRestoreSpan(record, r.lastRestoreStartTime, el.Time); err == nil {
+						r.lastRestoreStartTime = ""
+						if r.nextTraces != nil && td.SpanCount() > 0 {
+							_ = r.consumeTraces(context.Background(), td, failed)
+						}
+					}
+				}
+			}
+		// An extension registered.
+		case string(telemetryapi.PlatformExtension):
+			if record, ok := el.Record.(map[string]any); ok {
+				r.trackExtension(record)
+			}
 		// Function invocation started.
-		// case "platform.start":
+		case string(telemetryapi.PlatformStart):
+			if record, ok := el.Record.(map[string]any); ok {
+				if version, _ := record["version"].(string); version != "" {
+					r.faasFunctionVersion = version
+				}
+				r.startInvocation(el.Time, record)
+			}
 		// The runtime finished processing an event with either success or failure.
-		// case "platform.runtimeDone":
+		case string(telemetryapi.PlatformRuntimeDone):
+			if record, ok := el.Record.(map[string]any); ok {
+				r.finishInvocation(el.Time, record)
+			}
 		// A report of function invocation.
-		// case "platform.report":
-		// Runtime restore started (reserved for future use)
-		// case "platform.restoreStart":
-		// Runtime restore completed (reserved for future use)
-		// case "platform.restoreRuntimeDone":
-		// Report of runtime restore (reserved for future use)
-		// case "platform.restoreReport":
-		// The extension subscribed to the Telemetry API.
-		// case "platform.telemetrySubscription":
-		// Lambda dropped log entries.
-		// case "platform.logsDropped":
+		case string(telemetryapi.PlatformReport):
+			if record, ok := el.Record.(map[string]any); ok {
+				if td, ok := r.reportInvocation(record); ok && r.nextTraces != nil {
+					_ = r.consumeTraces(context.Background(), td, failed)
+				}
+			}
+		}
+	}
+	if r.nextMetrics != nil && r.emf {
+		r.extractEMFMetrics(slice)
 	}
+
+	// Logs are created before the metrics are recorded, so that the log severity counters are exported
+	// with the request's other metrics.
+	var logs plog.Logs
+	logsCreated := false
+	if r.nextLogs != nil || (r.logSeverityMetrics && r.nextMetrics != nil) {
+		if created, err := r.createLogs(slice); err == nil {
+			logs = created
+			logsCreated = true
+			if r.logSeverityMetrics {
+				r.recordLogRecords(logs)
+			}
+			r.dropLogSources(logs)
+		}
+	}
+
 	// Metrics
 	if r.nextMetrics != nil {
 		r.recordMetrics(slice)
@@ -319,19 +565,10 @@ func (r *telemetryAPIReceiver) httpHandler(w http.ResponseWriter, req *http.Requ
 	}
 
 	// Logs
-	if r.nextLogs != nil {
-		if logs, err := r.createLogs(slice); err == nil {
-			if logs.LogRecordCount() > 0 {
-				err := r.nextLogs.ConsumeLogs(context.Background(), logs)
-				if err != nil {
-					r.logger.Error("error receiving logs", zap.Error(err))
-				}
-			}
-		}
+	if r.nextLogs != nil && logsCreated && logs.LogRecordCount() > 0 {
+		_ = r.consumeLogs(context.Background(), logs, failed)
 	}
-
-	r.logger.Debug("logEvents received", zap.Int("count", len(slice)), zap.Int64("queue_length", r.queue.Len()))
-	slice = nil
+	r.scheduleMultilineFlush()
 }
 
 func (r *telemetryAPIReceiver) getRecordRequestId(record map[string]interface{}) string {
@@ -357,9 +594,10 @@ func (r *telemetryAPIReceiver) updateCurrentRequestId(requestId string) {
 }
 
 func (r *telemetryAPIReceiver) recordMetrics(slice []event) {
+	builders := r.faaSMetricBuilders
 	for _, el := range slice {
 		record, ok := el.Record.(map[string]any)
-		if !ok {
+		if !ok || el.stateOnly {
 			continue
 		}
 
@@ -371,13 +609,38 @@ func (r *telemetryAPIReceiver) recordMetrics(slice []event) {
 
 		switch el.Type {
 		case string(telemetryapi.PlatformInitStart):
-			r.faaSMetricBuilders.coldstartsMetric.Add(1)
+			r.pendingColdstart = true
+			builders.coldstartsMetric.AddWithDimensions(1, r.metricAttributes(el.Type, record))
 		case string(telemetryapi.PlatformInitReport):
+			attrs := r.metricAttributes(el.Type, record)
+			status, _ := record["status"].(string)
+			if status == telemetryFailureStatus || status == telemetryErrorStatus {
+				builders.errorsMetric.AddWithDimensions(1, attrs)
+			} else if status == telemetryTimeoutStatus {
+				builders.timeoutsMetric.AddWithDimensions(1, attrs)
+			}
+
+			metrics, ok := record["metrics"].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			durationMs, ok := metrics["durationMs"].(float64)
+			if !ok {
+				continue
+			}
+
+			builders.initDurationMetric.RecordWithDimensions(durationMs/1000.0, attrs)
+		case string(telemetryapi.PlatformRestoreStart):
+			r.pendingColdstart = true
+			builders.coldstartsMetric.AddWithDimensions(1, r.metricAttributes(el.Type, record))
+		case string(telemetryapi.PlatformRestoreReport):
+			attrs := r.metricAttributes(el.Type, record)
 			status, _ := record["status"].(string)
 			if status == telemetryFailureStatus || status == telemetryErrorStatus {
-				r.faaSMetricBuilders.errorsMetric.Add(1)
+				builders.errorsMetric.AddWithDimensions(1, attrs)
 			} else if status == telemetryTimeoutStatus {
-				r.faaSMetricBuilders.timeoutsMetric.Add(1)
+				builders.timeoutsMetric.AddWithDimensions(1, attrs)
 			}
 
 			metrics, ok := record["metrics"].(map[string]any)
@@ -390,26 +653,78 @@ func (r *telemetryAPIReceiver) recordMetrics(slice []event) {
 				continue
 			}
 
-			r.faaSMetricBuilders.initDurationMetric.Record(durationMs / 1000.0)
+			builders.restoreDurationMetric.RecordWithDimensions(durationMs/1000.0, attrs)
 		case string(telemetryapi.PlatformReport):
+			attrs := r.metricAttributes(el.Type, record)
+			if r.oomMetrics.detect(el.Type, record) {
+				builders.outOfMemoryMetric.AddWithDimensions(1, attrs)
+			}
+
 			metrics, ok := record["metrics"].(map[string]any)
 			if !ok {
 				continue
 			}
 
+			// The time between the end of the runtime and the report is spent by the extensions.
+			requestID := r.getRecordRequestId(record)
+			if runtimeDurationMs, ok := r.runtimeDurations[requestID]; ok {
+				delete(r.runtimeDurations, requestID)
+				if durationMs, ok := metrics["durationMs"].(float64); ok {
+					builders.extensionOverheadMetric.RecordWithDimensions(max(durationMs-runtimeDurationMs, 0)/1000.0, attrs)
+				}
+			}
+
+			memorySizeMB, hasMemorySize := metrics[string(telemetryapi.MetricMemorySizeMB)].(float64)
 			maxMemoryUsedMb, ok := metrics["maxMemoryUsedMB"].(float64)
 			if ok {
-				r.faaSMetricBuilders.memUsageMetric.Record(maxMemoryUsedMb * 1000000.0)
+				builders.memUsageMetric.RecordWithDimensions(maxMemoryUsedMb*1000000.0, attrs)
+				if hasMemorySize && memorySizeMB > 0 {
+					builders.memUtilizationMetric.RecordWithDimensions(maxMemoryUsedMb/memorySizeMB, attrs)
+				}
+			}
+
+			billedDurationMs, ok := metrics[string(telemetryapi.MetricBilledDurationMs)].(float64)
+			if !ok {
+				continue
+			}
+			builders.billedDurationMetric.RecordWithDimensions(billedDurationMs/1000.0, attrs)
+
+			if !hasMemorySize {
+				continue
+			}
+			// Lambda bills memory in GB of 1024 MB.
+			gbSeconds := memorySizeMB / 1024.0 * billedDurationMs / 1000.0
+			builders.gbSecondsMetric.AddDoubleWithDimensions(gbSeconds, attrs)
+			if r.prices != nil {
+				builders.estimatedCostMetric.AddDoubleWithDimensions(gbSeconds*r.prices.GBSecond+r.prices.Request, attrs)
+			}
+		case string(telemetryapi.PlatformLogsDropped):
+			if droppedRecords, ok := record["droppedRecords"].(float64); ok {
+				r.telemetryMetrics.droppedRecordsMetric.Add(int64(droppedRecords))
+			}
+			if droppedBytes, ok := record["droppedBytes"].(float64); ok {
+				r.telemetryMetrics.droppedBytesMetric.Add(int64(droppedBytes))
+			}
+		case string(telemetryapi.PlatformTelemetrySubscription):
+			if state, _ := record["state"].(string); state != "" {
+				attrs := pcommon.NewMap()
+				attrs.PutStr(attributeSubscriptionState, state)
+				r.telemetryMetrics.subscriptionsMetric.AddWithAttributes(1, attrs)
 			}
 		case string(telemetryapi.PlatformRuntimeDone):
+			attrs := r.metricAttributes(el.Type, record)
+			r.pendingColdstart = false
 			status, _ := record["status"].(string)
 
 			if status == telemetrySuccessStatus {
-				r.faaSMetricBuilders.invocationsMetric.Add(1)
+				builders.invocationsMetric.AddWithDimensions(1, attrs)
 			} else if status == telemetryFailureStatus || status == telemetryErrorStatus {
-				r.faaSMetricBuilders.errorsMetric.Add(1)
+				builders.errorsMetric.AddWithDimensions(1, attrs)
 			} else if status == telemetryTimeoutStatus {
-				r.faaSMetricBuilders.timeoutsMetric.Add(1)
+				builders.timeoutsMetric.AddWithDimensions(1, attrs)
+			}
+			if r.oomMetrics.detect(el.Type, record) {
+				builders.outOfMemoryMetric.AddWithDimensions(1, attrs)
 			}
 
 			metrics, ok := record["metrics"].(map[string]any)
@@ -419,24 +734,81 @@ func (r *telemetryAPIReceiver) recordMetrics(slice []event) {
 
 			durationMs, ok := metrics["durationMs"].(float64)
 			if ok {
-				r.faaSMetricBuilders.invokeDurationMetric.Record(durationMs / 1000.0)
+				builders.invokeDurationMetric.RecordWithDimensions(durationMs/1000.0, attrs)
+				if requestID := r.getRecordRequestId(record); requestID != "" {
+					r.runtimeDurations[requestID] = durationMs
+				}
 			}
 		}
 	}
 }
 
+// metricAttributes returns the values of all supported metric dimensions known for the event.
+// Each metric builder only keeps the dimensions it is configured with.
+func (r *telemetryAPIReceiver) metricAttributes(eventType string, record map[string]any) pcommon.Map {
+	attrs := pcommon.NewMap()
+	if status, _ := record["status"].(string); status != "" {
+		attrs.PutStr(dimensionStatus, status)
+	}
+	if errorType, _ := record["errorType"].(string); errorType != "" {
+		attrs.PutStr(string(semconv.ErrorTypeKey), errorType)
+	}
+	if initType, _ := record["initializationType"].(string); initType != "" {
+		attrs.PutStr(dimensionInitializationType, initType)
+	} else if r.lambdaInitType != lambdalifecycle.Unknown {
+		attrs.PutStr(dimensionInitializationType, r.lambdaInitType.String())
+	}
+	if r.faasFunctionVersion != "" {
+		attrs.PutStr(string(semconv.FaaSVersionKey), r.faasFunctionVersion)
+	}
+
+	coldstart := r.pendingColdstart
+	switch eventType {
+	case string(telemetryapi.PlatformInitStart), string(telemetryapi.PlatformInitReport),
+		string(telemetryapi.PlatformRestoreStart), string(telemetryapi.PlatformRestoreReport):
+		coldstart = true
+	case string(telemetryapi.PlatformReport):
+		// The report of the first invocation after a cold start carries the init duration.
+		metrics, _ := record["metrics"].(map[string]any)
+		_, coldstart = metrics[string(telemetryapi.MetricInitDurationMs)]
+	}
+	attrs.PutBool(string(semconv.FaaSColdstartKey), coldstart)
+	return attrs
+}
+
 func (r *telemetryAPIReceiver) createLogs(slice []event) (plog.Logs, error) {
-	log := plog.NewLogs()
-	resourceLog := log.ResourceLogs().AppendEmpty()
-	r.resource.CopyTo(resourceLog.Resource())
-	scopeLog := resourceLog.ScopeLogs().AppendEmpty()
-	scopeLog.Scope().SetName(scopeName)
+	log := r.newSourceLogs()
 	for _, el := range slice {
+		source := eventSource(el.Type)
+		if r.multiline != nil && r.multiline.hasPending && el.Type == string(telemetryapi.PlatformRuntimeDone) {
+			r.multiline.flush(log.scopeLogs(telemetryapi.Function))
+		}
+		if el.stateOnly {
+			r.trackLogContext(el)
+			continue
+		}
+		if record, ok := el.Record.(map[string]any); ok && r.oomLogs.detect(el.Type, record) {
+			r.appendOutOfMemoryLog(log.scopeLogs(source), el, record)
+		}
+		if el.emf && r.emfDropLogs {
+			continue
+		}
+		line, isLine := el.Record.(string)
+		multiline := r.multiline != nil && isLine && el.Type == string(telemetryapi.Function)
+		if multiline && r.multiline.join(line) {
+			continue
+		}
 		if !r.logReport && el.Type == string(telemetryapi.PlatformReport) {
+			r.forgetLogTraceContext(el)
 			continue
 		}
 		r.logger.Debug(fmt.Sprintf("Event: %s", el.Type), zap.Any("event", el))
-		logRecord := scopeLog.LogRecords().AppendEmpty()
+		var logRecord plog.LogRecord
+		if multiline {
+			logRecord = r.multiline.begin(log.scopeLogs(source))
+		} else {
+			logRecord = log.scopeLogs(source).LogRecords().AppendEmpty()
+		}
 		logRecord.Attributes().PutStr("type", el.Type)
 		if t, err := time.Parse(time.RFC3339, el.Time); err == nil {
 			logRecord.SetTimestamp(pcommon.NewTimestampFromTime(t))
@@ -454,6 +826,9 @@ func (r *telemetryAPIReceiver) createLogs(slice []event) (plog.Logs, error) {
 				r.updateCurrentRequestId(requestId)
 			}
 
+			if requestId == "" {
+				requestId = el.requestID
+			}
 			if requestId == "" {
 				requestId = r.getCurrentRequestId()
 			}
@@ -468,6 +843,9 @@ func (r *telemetryAPIReceiver) createLogs(slice []event) (plog.Logs, error) {
 				}
 			}
 
+			r.rememberLogTraceContext(el.Type, record, requestId)
+			r.setLogTraceContext(logRecord, record, requestId)
+
 			// in JSON format https://docs.aws.amazon.com/lambda/latest/dg/telemetry-schema-reference.html#telemetry-api-function
 			if timestamp, ok := record["timestamp"].(string); ok {
 				if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
@@ -494,42 +872,64 @@ func (r *telemetryAPIReceiver) createLogs(slice []event) (plog.Logs, error) {
 					}
 				}
 
+				structured := r.structuredPlatformLogs && putPlatformLogAttributes(logRecord.Attributes(), record)
 				message := createPlatformMessage(requestId, r.faasFunctionVersion, el.Type, record)
-				if message != "" {
+				if message != "" && (r.platformLogBody || !structured) {
 					logRecord.Body().SetStr(message)
 				}
-			} else if line, ok := record["message"].(string); ok {
-				logRecord.Body().SetStr(line)
+			} else {
+				if line, ok := record["message"].(string); ok {
+					logRecord.Body().SetStr(line)
 
-				for key, value := range record {
-					switch key {
-					case "level", "message", "requestId", "timestamp", "type":
-						continue
-					default:
-						attr, _ := logRecord.Attributes().GetOrPutEmpty(key)
-						if err := attr.FromRaw(value); err != nil {
-							logRecord.Attributes().Remove(key)
-							r.logger.Warn("Failed while converting field to attribute", zap.String("key", key), zap.Error(err))
+					for key, value := range record {
+						switch key {
+						case "level", "message", "requestId", "timestamp", "type":
 							continue
+						default:
+							attr, _ := logRecord.Attributes().GetOrPutEmpty(key)
+							if err := attr.FromRaw(value); err != nil {
+								logRecord.Attributes().Remove(key)
+								r.logger.Warn("Failed while converting field to attribute", zap.String("key", key), zap.Error(err))
+								continue
+							}
 						}
 					}
 				}
+				if exception, ok := parseLogException(record); ok && el.Type == string(telemetryapi.Function) {
+					setLogException(logRecord, exception)
+				}
 			}
 		} else {
-			if requestId := r.getCurrentRequestId(); requestId != "" {
-				logRecord.Attributes().PutStr(string(semconv.FaaSInvocationIDKey), requestId)
+			requestId := el.requestID
+			if requestId == "" {
+				requestId = r.getCurrentRequestId()
 			}
 
 			// in plain text https://docs.aws.amazon.com/lambda/latest/dg/telemetry-schema-reference.html#telemetry-api-function
 			if line, ok := el.Record.(string); ok {
 				logRecord.Body().SetStr(line)
+				if r.textLogParser != nil {
+					if parsed, ok := r.textLogParser(line); ok {
+						logRecord.Body().SetStr(parsed.message)
+						logRecord.SetTimestamp(pcommon.NewTimestampFromTime(parsed.timestamp))
+						logRecord.SetSeverityNumber(severityTextToNumber(parsed.level))
+						logRecord.SetSeverityText(logRecord.SeverityNumber().String())
+						requestId = parsed.requestID
+					}
+				}
 			}
+
+			if requestId != "" {
+				logRecord.Attributes().PutStr(string(semconv.FaaSInvocationIDKey), requestId)
+			}
+			r.setLogTraceContext(logRecord, nil, requestId)
 		}
 		if el.Type == string(telemetryapi.PlatformRuntimeDone) {
 			r.updateCurrentRequestId("")
 		}
+		r.forgetLogTraceContext(el)
 	}
-	return log, nil
+	return log.logs, nil
 }
 
 func createPlatformMessage(requestId string, functionVersion string, eventType string, record map[string]interface{}) string {
@@ -713,16 +1113,33 @@ func (r *telemetryAPIReceiver) registerLogsConsumer(next consumer.Logs) {
 }
 
 func (r *telemetryAPIReceiver) createPlatformInitSpan(record map[string]any, start, end string) (ptrace.Traces, error) {
+	return r.createPlatformPhaseSpan(fmt.Sprintf("init %s", r.faasName), telemetryapi.PlatformInitStart, record, start, end)
+}
+
+// createPlatformRestoreSpan creates the span covering the restore of a SnapStart snapshot,
+// which takes the place of the init phase for functions using lambdalifecycle.SnapStart.
+func (r *telemetryAPIReceiver) createPlatformRestoreSpan(record map[string]any, start, end string) (ptrace.Traces, error) {
+	return r.createPlatformPhaseSpan(fmt.Sprintf("restore %s", r.faasName), telemetryapi.PlatformRestoreStart, record, start, end)
+}
+
+func (r *telemetryAPIReceiver) createPlatformPhaseSpan(name string, startEvent telemetryapi.EventType, record map[string]any, start, end string) (ptrace.Traces, error) {
+	var tracing *traceContext
+	if tc, ok := parseTracing(record); ok {
+		tracing = &tc
+	}
+
 	traceData := ptrace.NewTraces()
+	if tracing != nil && !tracing.sampled {
+		return traceData, nil
+	}
 	rs := traceData.ResourceSpans().AppendEmpty()
 	r.resource.CopyTo(rs.Resource())
 
 	ss := rs.ScopeSpans().AppendEmpty()
 	ss.Scope().SetName(scopeName)
 	span := ss.Spans().AppendEmpty()
-	span.SetTraceID(newTraceID())
-	span.SetSpanID(newSpanID())
-	span.SetName(fmt.Sprintf("init %s", r.faasName))
+	r.setSpanContext(span, tracing, "", string(startEvent), start)
+	span.SetName(name)
 	span.SetKind(ptrace.SpanKindInternal)
 	span.Attributes().PutBool(string(semconv.FaaSColdstartKey), true)
 	startTime, err := time.Parse(time.RFC3339, start)
@@ -746,9 +1163,60 @@ func (r *telemetryAPIReceiver) createPlatformInitSpan(record map[string]any, sta
 			span.Attributes().PutStr(string(semconv.ErrorTypeKey), status)
 		}
 	}
+
+	spans, _ := record["spans"].([]any)
+	r.appendPlatformChildSpans(ss, span.TraceID(), span.SpanID(), spans)
 	return traceData, nil
 }
 
+// createPlatformInitReportSpans creates the init phase spans of a platform.initReport event,
+// as children of the init span already created from platform.initRuntimeDone.
+func (r *telemetryAPIReceiver) createPlatformInitReportSpans(record map[string]any) ptrace.Traces {
+	traceData := ptrace.NewTraces()
+	spans, _ := record["spans"].([]any)
+	if len(spans) == 0 || r.lastInitSpanID.IsEmpty() {
+		return traceData
+	}
+	rs := traceData.ResourceSpans().AppendEmpty()
+	r.resource.CopyTo(rs.Resource())
+
+	ss := rs.ScopeSpans().AppendEmpty()
+	ss.Scope().SetName(scopeName)
+	r.appendPlatformChildSpans(ss, r.lastInitTraceID, r.lastInitSpanID, spans)
+	return traceData
+}
+
+// appendPlatformChildSpans converts the entries of a platform record `spans` array, such as
+// responseLatency or runtimeOverhead, into children of the given span.
+// See https://docs.aws.amazon.com/lambda/latest/dg/telemetry-schema-reference.html#Span
+func (r *telemetryAPIReceiver) appendPlatformChildSpans(ss ptrace.ScopeSpans, traceID pcommon.TraceID, parentID pcommon.SpanID, spans []any) {
+	for _, s := range spans {
+		entry, ok := s.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := entry["name"].(string)
+		start, _ := entry["start"].(string)
+		durationMs, ok := entry["durationMs"].(float64)
+		if name == "" || !ok {
+			continue
+		}
+		startTime, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			continue
+		}
+
+		span := ss.Spans().AppendEmpty()
+		span.SetTraceID(traceID)
+		span.SetSpanID(r.spanIDFor(parentID.String(), name, start))
+		span.SetParentSpanID(parentID)
+		span.SetName(name)
+		span.SetKind(ptrace.SpanKindInternal)
+		span.SetStartTimestamp(pcommon.NewTimestampFromTime(startTime))
+		span.SetEndTimestamp(pcommon.NewTimestampFromTime(startTime.Add(time.Duration(durationMs * float64(time.Millisecond)))))
+	}
+}
+
 func getMetricsTemporality(cfg *Config) pmetric.AggregationTemporality {
 	temporality := strings.ToLower(cfg.MetricsTemporality)
 	if temporality == "" {
@@ -769,34 +1237,7 @@ func newTelemetryAPIReceiver(
 	cfg *Config,
 	set receiver.Settings,
 ) (*telemetryAPIReceiver, error) {
-	envResourceMap := map[string]string{
-		"AWS_LAMBDA_FUNCTION_MEMORY_SIZE": string(semconv.FaaSMaxMemoryKey),
-		"AWS_LAMBDA_FUNCTION_VERSION":     string(semconv.FaaSVersionKey),
-		"AWS_REGION":                      string(semconv.FaaSInvokedRegionKey),
-	}
-	r := pcommon.NewResource()
-	r.Attributes().PutStr(string(semconv.FaaSInvokedProviderKey), semconv.FaaSInvokedProviderAWS.Value.AsString())
-	if val, ok := os.LookupEnv("AWS_LAMBDA_FUNCTION_NAME"); ok {
-		r.Attributes().PutStr(string(semconv.ServiceNameKey), val)
-		r.Attributes().PutStr(string(semconv.FaaSNameKey), val)
-	} else {
-		r.Attributes().PutStr(string(semconv.ServiceNameKey), "unknown_service")
-	}
-
-	serviceInstanceID, ok := set.Resource.Attributes().Get(string(semconv.ServiceInstanceIDKey))
-	if ok {
-		r.Attributes().PutStr(string(semconv.ServiceInstanceIDKey), serviceInstanceID.Str())
-	}
-
-	if val, ok := os.LookupEnv("OTEL_SERVICE_NAME"); ok {
-		r.Attributes().PutStr(string(semconv.ServiceNameKey), val)
-	}
-
-	for env, resourceAttribute := range envResourceMap {
-		if val, ok := os.LookupEnv(env); ok {
-			r.Attributes().PutStr(resourceAttribute, val)
-		}
-	}
+	r := newResource(set)
 
 	var subscribedTypes []telemetryapi.EventType
 	for _, val := range cfg.Types {
@@ -812,21 +1253,79 @@ func newTelemetryAPIReceiver(
 
 	lambdaInitType := lambdalifecycle.InitTypeFromEnv(lambdalifecycle.InitTypeEnvVar)
 
+	var prices *ArchitecturePrices
+	if p, ok := cfg.Cost.Prices[currentArchitecture()]; ok {
+		prices = &p
+	}
+
+	faaSMetricBuilders := NewFaaSMetricBuilders(pcommon.NewTimestampFromTime(time.Now()), getMetricsTemporality(cfg))
+	faaSMetricBuilders.configure(cfg.Metrics)
+	telemetryMetrics := NewTelemetryMetricBuilders(pcommon.NewTimestampFromTime(time.Now()), getMetricsTemporality(cfg))
+
+	schemaVersion := telemetryapi.SchemaVersion(cfg.SchemaVersion)
+	if schemaVersion == "" {
+		schemaVersion = telemetryapi.SchemaVersionLatest
+	}
+
+	protocol := cfg.Protocol
+	if protocol == "" {
+		protocol = protocolHTTP
+	}
+
+	var multiline *multilineAggregator
+	if cfg.Multiline != nil {
+		var err error
+		if multiline, err = newMultilineAggregator(cfg.Multiline); err != nil {
+			return nil, err
+		}
+	}
+
 	return &telemetryAPIReceiver{
-		logger:             set.Logger,
-		queue:              queue.New(initialQueueSize),
-		extensionID:        cfg.extensionID,
-		port:               cfg.Port,
-		types:              subscribedTypes,
-		resource:           r,
-		faaSMetricBuilders: NewFaaSMetricBuilders(pcommon.NewTimestampFromTime(time.Now()), getMetricsTemporality(cfg)),
-		lambdaInitType:     lambdaInitType,
-		logReport:          cfg.LogReport,
-		exportInterval:     time.Duration(cfg.ExportInterval) * time.Millisecond,
-		stopCh:             make(chan struct{}),
+		logger:                 set.Logger,
+		queue:                  queue.New(initialQueueSize),
+		broker:                 defaultBroker,
+		extensionID:            cfg.extensionID,
+		port:                   cfg.Port,
+		types:                  subscribedTypes,
+		buffering:              cfg.Buffering.bufferingCfg(),
+		schemaVersion:          schemaVersion,
+		resource:               r,
+		faasName:               os.Getenv("AWS_LAMBDA_FUNCTION_NAME"),
+		invocations:            make(map[string]*invocation),
+		runtimeDurations:       make(map[string]float64),
+		inFlight:               make(map[string]struct{}),
+		logTraceContexts:       make(map[string]traceContext),
+		textLogParser:          newTextLogParser(cfg.TextLogFormat),
+		multiline:              multiline,
+		faaSMetricBuilders:     faaSMetricBuilders,
+		telemetryMetrics:       telemetryMetrics,
+		lambdaInitType:         lambdaInitType,
+		deterministicIDs:       cfg.IDGeneration == idGenerationDeterministic,
+		prices:                 prices,
+		logReport:              cfg.LogReport,
+		structuredPlatformLogs: cfg.PlatformLogFormat == platformLogFormatStructured || cfg.PlatformLogFormat == platformLogFormatStructuredNoBody,
+		platformLogBody:        cfg.PlatformLogFormat != platformLogFormatStructuredNoBody,
+		logSources:             cfg.LogSources,
+		protocol:               protocol,
+		emf:                    cfg.EMF.Enabled,
+		emfDropLogs:            cfg.EMF.DropLogs,
+		emfMetrics:             pmetric.NewScopeMetricsSlice(),
+		logSeverityMetrics:     cfg.LogSeverityMetrics,
+		backpressure:           cfg.Backpressure.Enabled,
+		maxRetries:             cfg.Backpressure.MaxRetries,
+		exportInterval:         time.Duration(cfg.ExportInterval) * time.Millisecond,
+		stopCh:                 make(chan struct{}),
 	}, nil
 }
 
+// currentArchitecture returns the Lambda name of the architecture the collector, and therefore the function, runs on.
+func currentArchitecture() string {
+	if runtime.GOARCH == "arm64" {
+		return architectureArm
+	}
+	return architectureX86
+}
+
 func listenOnAddress() string {
 	envAwsLocal, ok := os.LookupEnv("AWS_SAM_LOCAL")
 	if ok && envAwsLocal == "true" {
@@ -16,6 +16,8 @@ package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http/httptest"
 	"strings"
 	"testing"
@@ -23,6 +25,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
@@ -163,6 +166,339 @@ func TestRecordMetrics(t *testing.T) {
 	require.True(t, foundDuration)
 }
 
+func TestRecordRestoreMetrics(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(
+		&Config{},
+		receivertest.NewNopSettings(Type),
+	)
+	require.NoError(t, err)
+
+	slice := []event{
+		{
+			Type:   "platform.restoreStart",
+			Record: map[string]any{},
+		},
+		{
+			Type: "platform.restoreReport",
+			Record: map[string]any{
+				"status": "success",
+				"metrics": map[string]any{
+					"durationMs": 250.0,
+				},
+			},
+		},
+	}
+
+	r.recordMetrics(slice)
+
+	c := &mockConsumer{}
+	r.registerMetricsConsumer(c)
+	err = r.flushMetrics(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, c.metricBatches, 1)
+	sm := c.metricBatches[0].ResourceMetrics().At(0).ScopeMetrics().At(0)
+	require.Equal(t, 2, sm.Metrics().Len()) // coldstarts and restore duration
+
+	foundRestoreDuration := false
+	for i := 0; i < sm.Metrics().Len(); i++ {
+		m := sm.Metrics().At(i)
+		if m.Name() == RestoreDurationName {
+			foundRestoreDuration = true
+			require.Equal(t, 0.25, m.Histogram().DataPoints().At(0).Sum())
+		}
+	}
+	require.True(t, foundRestoreDuration)
+}
+
+func TestRecordCostMetrics(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		cost         CostConfig
+		expectedCost float64
+	}{
+		{
+			desc: "without prices",
+		},
+		{
+			desc: "with prices",
+			cost: CostConfig{
+				Prices: map[string]ArchitecturePrices{
+					currentArchitecture(): {GBSecond: 0.0001, Request: 0.01},
+				},
+			},
+			expectedCost: 0.5*0.0001 + 0.01,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := newTelemetryAPIReceiver(
+				&Config{Cost: tc.cost},
+				receivertest.NewNopSettings(Type),
+			)
+			require.NoError(t, err)
+
+			r.recordMetrics([]event{
+				{
+					Type: "platform.report",
+					Record: map[string]any{
+						"requestId": "test-id",
+						"metrics": map[string]any{
+							"durationMs":       995.3,
+							"billedDurationMs": 1000.0,
+							"memorySizeMB":     512.0,
+						},
+					},
+				},
+			})
+
+			c := &mockConsumer{}
+			r.registerMetricsConsumer(c)
+			require.NoError(t, r.flushMetrics(context.Background()))
+			require.Len(t, c.metricBatches, 1)
+
+			found := map[string]pmetric.Metric{}
+			sm := c.metricBatches[0].ResourceMetrics().At(0).ScopeMetrics().At(0)
+			for i := 0; i < sm.Metrics().Len(); i++ {
+				found[sm.Metrics().At(i).Name()] = sm.Metrics().At(i)
+			}
+
+			require.Contains(t, found, BilledDurationName)
+			require.Equal(t, 1.0, found[BilledDurationName].Histogram().DataPoints().At(0).Sum())
+			require.Contains(t, found, GBSecondsName)
+			require.Equal(t, 0.5, found[GBSecondsName].Sum().DataPoints().At(0).DoubleValue())
+			if tc.expectedCost == 0 {
+				require.NotContains(t, found, EstimatedCostName)
+			} else {
+				require.Contains(t, found, EstimatedCostName)
+				require.InDelta(t, tc.expectedCost, found[EstimatedCostName].Sum().DataPoints().At(0).DoubleValue(), 1e-12)
+			}
+		})
+	}
+}
+
+func TestRecordExtensionOverheadMetric(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	r.recordMetrics([]event{
+		{
+			Type:   "platform.runtimeDone",
+			Record: map[string]any{"requestId": "a", "status": "success", "metrics": map[string]any{"durationMs": 200.0}},
+		},
+		{
+			Type:   "platform.runtimeDone",
+			Record: map[string]any{"requestId": "b", "status": "success", "metrics": map[string]any{"durationMs": 100.0}},
+		},
+		{
+			Type:   "platform.report",
+			Record: map[string]any{"requestId": "b", "metrics": map[string]any{"durationMs": 150.0}},
+		},
+		{
+			Type:   "platform.report",
+			Record: map[string]any{"requestId": "a", "metrics": map[string]any{"durationMs": 210.0}},
+		},
+		{
+			Type:   "platform.report",
+			Record: map[string]any{"requestId": "c", "metrics": map[string]any{"durationMs": 50.0}},
+		},
+	})
+	require.Empty(t, r.runtimeDurations)
+
+	c := &mockConsumer{}
+	r.registerMetricsConsumer(c)
+	require.NoError(t, r.flushMetrics(context.Background()))
+	require.Len(t, c.metricBatches, 1)
+
+	sm := c.metricBatches[0].ResourceMetrics().At(0).ScopeMetrics().At(0)
+	var overhead pmetric.Metric
+	for i := 0; i < sm.Metrics().Len(); i++ {
+		if sm.Metrics().At(i).Name() == ExtensionOverheadName {
+			overhead = sm.Metrics().At(i)
+		}
+	}
+	dp := overhead.Histogram().DataPoints().At(0)
+	require.Equal(t, uint64(2), dp.Count())
+	require.InDelta(t, 0.06, dp.Sum(), 1e-9)
+}
+
+func TestExpireRuntimeDurations(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+	now := time.Now()
+
+	// The platform.report event of "a" never arrives.
+	r.recordMetrics([]event{
+		{
+			Type:   "platform.runtimeDone",
+			Record: map[string]any{"requestId": "a", "status": "success", "metrics": map[string]any{"durationMs": 200.0}},
+		},
+	})
+	r.expireInvocations(now)
+	require.Contains(t, r.runtimeDurations, "a")
+	r.expireInvocations(now.Add(maxInvocationAge))
+	require.Empty(t, r.runtimeDurations)
+}
+
+func TestRecordMetricsDimensions(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(
+		&Config{
+			Metrics: map[string]MetricConfig{
+				"faas.errors": {Dimensions: []string{"error.type", "faas.coldstart"}},
+			},
+		},
+		receivertest.NewNopSettings(Type),
+	)
+	require.NoError(t, err)
+
+	r.recordMetrics([]event{
+		{Type: "platform.initStart", Record: map[string]any{}},
+		{Type: "platform.runtimeDone", Record: map[string]any{"status": "error", "errorType": "Runtime.ExitError"}},
+		{Type: "platform.runtimeDone", Record: map[string]any{"status": "error", "errorType": "Runtime.ExitError"}},
+		{Type: "platform.runtimeDone", Record: map[string]any{"status": "failure", "errorType": "Runtime.OutOfMemory"}},
+	})
+
+	c := &mockConsumer{}
+	r.registerMetricsConsumer(c)
+	require.NoError(t, r.flushMetrics(context.Background()))
+	require.Len(t, c.metricBatches, 1)
+
+	sm := c.metricBatches[0].ResourceMetrics().At(0).ScopeMetrics().At(0)
+	var errors pmetric.Metric
+	for i := 0; i < sm.Metrics().Len(); i++ {
+		if sm.Metrics().At(i).Name() == semconv.FaaSErrorsName {
+			errors = sm.Metrics().At(i)
+		}
+	}
+	dps := errors.Sum().DataPoints()
+	require.Equal(t, 3, dps.Len())
+
+	type series struct {
+		errorType string
+		coldstart bool
+	}
+	values := map[series]int64{}
+	for i := 0; i < dps.Len(); i++ {
+		errorType, _ := dps.At(i).Attributes().Get(string(semconv.ErrorTypeKey))
+		coldstart, _ := dps.At(i).Attributes().Get(string(semconv.FaaSColdstartKey))
+		values[series{errorType.Str(), coldstart.Bool()}] = dps.At(i).IntValue()
+	}
+	require.Equal(t, map[series]int64{
+		{"Runtime.ExitError", true}:    1,
+		{"Runtime.ExitError", false}:   1,
+		{"Runtime.OutOfMemory", false}: 1,
+	}, values)
+}
+
+func TestTelemetryMetrics(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+	c := &mockConsumer{}
+	r.registerMetricsConsumer(c)
+	r.registerLogsConsumer(consumertest.NewErr(errors.New("logs pipeline down")))
+
+	requests := []string{
+		`[
+			{"time":"2022-10-12T00:00:00.000Z", "type":"platform.telemetrySubscription", "record": {"name":"collector", "state":"Subscribed", "types":["platform","function"]}},
+			{"time":"2022-10-12T00:00:01.000Z", "type":"platform.logsDropped", "record": {"reason":"Consumer seems to have fallen behind", "droppedRecords":12, "droppedBytes":4096}},
+			{"time":"2022-10-12T00:00:02.000Z", "type":"platform.logsDropped", "record": {"reason":"Consumer seems to have fallen behind", "droppedRecords":3, "droppedBytes":1024}},
+			{"time":"2022-10-12T00:00:03.000Z", "type":"function", "record": "hello"}
+		]`,
+		`not json`,
+	}
+	for _, body := range requests {
+		r.httpHandler(httptest.NewRecorder(), httptest.NewRequest("POST", "http://localhost/", strings.NewReader(body)))
+	}
+	require.NoError(t, r.flushMetrics(context.Background()))
+
+	found := map[string]pmetric.Metric{}
+	for _, md := range c.metricBatches {
+		sm := md.ResourceMetrics().At(0).ScopeMetrics().At(0)
+		for i := 0; i < sm.Metrics().Len(); i++ {
+			found[sm.Metrics().At(i).Name()] = sm.Metrics().At(i)
+		}
+	}
+
+	sumByAttribute := func(name string, key string) map[string]int64 {
+		require.Contains(t, found, name)
+		values := map[string]int64{}
+		dps := found[name].Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			v, _ := dps.At(i).Attributes().Get(key)
+			values[v.Str()] = dps.At(i).IntValue()
+		}
+		return values
+	}
+
+	require.Equal(t, map[string]int64{"": 15}, sumByAttribute(LogsDroppedRecordsName, ""))
+	require.Equal(t, map[string]int64{"": 5120}, sumByAttribute(LogsDroppedBytesName, ""))
+	require.Equal(t, map[string]int64{"Subscribed": 1}, sumByAttribute(SubscriptionsName, attributeSubscriptionState))
+	require.Equal(t, map[string]int64{
+		"platform.telemetrySubscription": 1,
+		"platform.logsDropped":           2,
+		"function":                       1,
+	}, sumByAttribute(EventsName, attributeEventType))
+	require.Equal(t, map[string]int64{"": 1}, sumByAttribute(UnmarshalFailuresName, ""))
+	require.Equal(t, map[string]int64{"logs": 1}, sumByAttribute(ConsumerErrorsName, attributeSignal))
+}
+
+func TestLogSeverityMetrics(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		enabled  bool
+		expected map[string]int64
+	}{
+		{
+			desc:    "enabled",
+			enabled: true,
+			expected: map[string]int64{
+				"function/17":  2,
+				"function/9":   1,
+				"extension/13": 1,
+			},
+		},
+		{
+			desc:     "disabled",
+			expected: map[string]int64{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			// Only the metrics pipeline is set up: the counters do not need the logs to be exported.
+			r, err := newTelemetryAPIReceiver(&Config{LogSeverityMetrics: tc.enabled}, receivertest.NewNopSettings(Type))
+			require.NoError(t, err)
+			sink := &consumertest.MetricsSink{}
+			r.registerMetricsConsumer(sink)
+
+			body := `[
+				{"time":"2022-10-12T00:00:00.000Z", "type":"platform.start", "record": {"requestId":"test-id"}},
+				{"time":"2022-10-12T00:00:01.000Z", "type":"function", "record": {"timestamp":"2022-10-12T00:00:01.000Z", "level":"ERROR", "message":"failed"}},
+				{"time":"2022-10-12T00:00:02.000Z", "type":"function", "record": {"timestamp":"2022-10-12T00:00:02.000Z", "level":"INFO", "message":"hello"}},
+				{"time":"2022-10-12T00:00:03.000Z", "type":"function", "record": {"errorType":"TypeError", "errorMessage":"x is undefined"}},
+				{"time":"2022-10-12T00:00:04.000Z", "type":"extension", "record": {"timestamp":"2022-10-12T00:00:04.000Z", "level":"WARN", "message":"slow"}}
+			]`
+			r.httpHandler(httptest.NewRecorder(), httptest.NewRequest("POST", "http://localhost/", strings.NewReader(body)))
+
+			counts := map[string]int64{}
+			for _, md := range sink.AllMetrics() {
+				metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+				for i := 0; i < metrics.Len(); i++ {
+					if metrics.At(i).Name() != LogRecordsName {
+						continue
+					}
+					dps := metrics.At(i).Sum().DataPoints()
+					for j := 0; j < dps.Len(); j++ {
+						eventType, _ := dps.At(j).Attributes().Get(attributeEventType)
+						severity, _ := dps.At(j).Attributes().Get(attributeSeverityNumber)
+						counts[fmt.Sprintf("%s/%d", eventType.Str(), severity.Int())] = dps.At(j).IntValue()
+					}
+				}
+			}
+			require.Equal(t, tc.expected, counts)
+		})
+	}
+}
+
 func TestMetricTimestampMatchesEventTime(t *testing.T) {
 	r, err := newTelemetryAPIReceiver(
 		&Config{ExportInterval: 60000},
@@ -342,6 +678,30 @@ func TestHandler(t *testing.T) {
 			]`,
 			expectedSpans: 1,
 		},
+		{
+			desc: "valid restore events",
+			body: `[
+				{"time":"2006-01-02T15:04:04.000Z", "type":"platform.restoreStart", "record": {}},
+				{"time":"2006-01-02T15:04:05.000Z", "type":"platform.restoreRuntimeDone", "record": {"status":"success"}}
+			]`,
+			expectedSpans: 1,
+		},
+		{
+			desc: "valid invocation events",
+			body: `[
+				{"time":"2006-01-02T15:04:04.000Z", "type":"platform.start", "record": {"requestId":"test-id"}},
+				{"time":"2006-01-02T15:04:05.000Z", "type":"platform.runtimeDone", "record": {"requestId":"test-id", "status":"success"}},
+				{"time":"2006-01-02T15:04:05.000Z", "type":"platform.report", "record": {"requestId":"test-id", "status":"success"}}
+			]`,
+			expectedSpans: 1,
+		},
+		{
+			desc: "invocation without report",
+			body: `[
+				{"time":"2006-01-02T15:04:04.000Z", "type":"platform.start", "record": {"requestId":"test-id"}},
+				{"time":"2006-01-02T15:04:05.000Z", "type":"platform.runtimeDone", "record": {"requestId":"test-id", "status":"success"}}
+			]`,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -408,6 +768,80 @@ func TestCreatePlatformInitSpan(t *testing.T) {
 	}
 }
 
+func TestCreatePlatformInitPhaseSpans(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(
+		&Config{},
+		receivertest.NewNopSettings(Type),
+	)
+	require.NoError(t, err)
+	consumer := mockConsumer{}
+	r.registerTracesConsumer(&consumer)
+
+	req := httptest.NewRequest("POST", "http://localhost:53612/someevent", strings.NewReader(`[
+		{"time":"2006-01-02T15:04:04.000Z", "type":"platform.initStart", "record": {}},
+		{"time":"2006-01-02T15:04:05.000Z", "type":"platform.initRuntimeDone", "record": {
+			"status": "success",
+			"spans": [{"name": "runtimeInit", "start": "2006-01-02T15:04:04.100Z", "durationMs": 800}]
+		}},
+		{"time":"2006-01-02T15:04:05.000Z", "type":"platform.initReport", "record": {
+			"status": "success",
+			"spans": [{"name": "extensionInit", "start": "2006-01-02T15:04:04.050Z", "durationMs": 40}]
+		}}
+	]`))
+	r.httpHandler(httptest.NewRecorder(), req)
+	require.Equal(t, 3, consumer.consumed)
+}
+
+func TestCreatePlatformInitSpanNotSampled(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(
+		&Config{},
+		receivertest.NewNopSettings(Type),
+	)
+	require.NoError(t, err)
+	consumer := mockConsumer{}
+	r.registerTracesConsumer(&consumer)
+
+	req := httptest.NewRequest("POST", "http://localhost:53612/someevent", strings.NewReader(`[
+		{"time":"2006-01-02T15:04:04.000Z", "type":"platform.initStart", "record": {}},
+		{"time":"2006-01-02T15:04:05.000Z", "type":"platform.initRuntimeDone", "record": {
+			"tracing": {"type": "X-Amzn-Trace-Id", "value": "Root=1-5e1b4151-43a0913a12345678901234f5;Parent=53995c3f42cd8ad8;Sampled=0"}
+		}}
+	]`))
+	r.httpHandler(httptest.NewRecorder(), req)
+	require.Equal(t, 0, consumer.consumed)
+	require.Empty(t, r.lastPlatformStartTime)
+	require.Empty(t, r.lastPlatformEndTime)
+
+	// A later init must not reuse the timestamps of the unsampled one.
+	req = httptest.NewRequest("POST", "http://localhost:53612/someevent", strings.NewReader(`[
+		{"time":"2006-01-02T15:05:05.000Z", "type":"platform.initRuntimeDone", "record": {}}
+	]`))
+	r.httpHandler(httptest.NewRecorder(), req)
+	require.Equal(t, 0, consumer.consumed)
+}
+
+func TestCreatePlatformRestoreSpan(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(
+		&Config{},
+		receivertest.NewNopSettings(Type),
+	)
+	require.NoError(t, err)
+	r.faasName = "test-func"
+
+	td, err := r.createPlatformRestoreSpan(map[string]any{"status": "error", "errorType": "Runtime.RestoreError"}, "2006-01-02T15:04:04.000Z", "2006-01-02T15:04:05.000Z")
+	require.NoError(t, err)
+	require.Equal(t, 1, td.SpanCount())
+	span := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	require.Equal(t, "restore test-func", span.Name())
+	require.Equal(t, ptrace.StatusCodeError, span.Status().Code())
+	errorType, ok := span.Attributes().Get(string(semconv.ErrorTypeKey))
+	require.True(t, ok)
+	require.Equal(t, "Runtime.RestoreError", errorType.Str())
+
+	_, err = r.createPlatformRestoreSpan(map[string]any{}, "", "2006-01-02T15:04:05.000Z")
+	require.Error(t, err)
+}
+
 func TestCreateLogs(t *testing.T) {
 	t.Parallel()
 
@@ -985,15 +1419,11 @@ func TestCreateLogs(t *testing.T) {
 				return
 			}
 			require.NoError(t, err)
-			require.Equal(t, 1, log.ResourceLogs().Len())
-			resourceLog := log.ResourceLogs().At(0)
-			require.Equal(t, 1, resourceLog.ScopeLogs().Len())
-			scopeLog := resourceLog.ScopeLogs().At(0)
-			require.Equal(t, scopeName, scopeLog.Scope().Name())
-			require.Equal(t, len(tc.expectedLogs), scopeLog.LogRecords().Len())
+			records := logRecords(t, log)
+			require.Equal(t, len(tc.expectedLogs), records.Len())
 
 			for i, expected := range tc.expectedLogs {
-				logRecord := scopeLog.LogRecords().At(i)
+				logRecord := records.At(i)
 
 				attr, ok := logRecord.Attributes().Get("type")
 				require.True(t, ok)
@@ -1265,14 +1695,10 @@ func TestCreateLogsWithLogReport(t *testing.T) {
 				require.Error(t, err)
 			} else {
 				require.NoError(t, err)
-				require.Equal(t, 1, log.ResourceLogs().Len())
-				resourceLog := log.ResourceLogs().At(0)
-				require.Equal(t, 1, resourceLog.ScopeLogs().Len())
-				scopeLog := resourceLog.ScopeLogs().At(0)
-				require.Equal(t, scopeName, scopeLog.Scope().Name())
-				require.Equal(t, tc.expectedLogRecords, scopeLog.LogRecords().Len())
-				if scopeLog.LogRecords().Len() > 0 {
-					logRecord := scopeLog.LogRecords().At(0)
+				records := logRecords(t, log)
+				require.Equal(t, tc.expectedLogRecords, records.Len())
+				if records.Len() > 0 {
+					logRecord := records.At(0)
 					attr, ok := logRecord.Attributes().Get("type")
 					require.True(t, ok)
 					require.Equal(t, tc.expectedType, attr.Str())
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-lambda/collector/lambdalifecycle"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/receiver"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+// executionEnvPrefix prefixes the runtime identifier in AWS_EXECUTION_ENV, e.g. AWS_Lambda_python3.12.
+const executionEnvPrefix = "AWS_Lambda_"
+
+// newResource returns the resource of the function's telemetry, described by the Lambda environment variables.
+func newResource(set receiver.Settings) pcommon.Resource {
+	envResourceMap := map[string]string{
+		"AWS_LAMBDA_FUNCTION_MEMORY_SIZE": string(semconv.FaaSMaxMemoryKey),
+		"AWS_LAMBDA_FUNCTION_VERSION":     string(semconv.FaaSVersionKey),
+		"AWS_REGION":                      string(semconv.FaaSInvokedRegionKey),
+	}
+	r := pcommon.NewResource()
+	r.Attributes().PutStr(string(semconv.FaaSInvokedProviderKey), semconv.FaaSInvokedProviderAWS.Value.AsString())
+	r.Attributes().PutStr(string(semconv.CloudProviderKey), semconv.CloudProviderAWS.Value.AsString())
+	r.Attributes().PutStr(string(semconv.CloudPlatformKey), semconv.CloudPlatformAWSLambda.Value.AsString())
+	r.Attributes().PutStr(string(semconv.HostArchKey), hostArch())
+	if val, ok := os.LookupEnv("AWS_LAMBDA_FUNCTION_NAME"); ok {
+		r.Attributes().PutStr(string(semconv.ServiceNameKey), val)
+		r.Attributes().PutStr(string(semconv.FaaSNameKey), val)
+	} else {
+		r.Attributes().PutStr(string(semconv.ServiceNameKey), "unknown_service")
+	}
+
+	serviceInstanceID, ok := set.Resource.Attributes().Get(string(semconv.ServiceInstanceIDKey))
+	if ok {
+		r.Attributes().PutStr(string(semconv.ServiceInstanceIDKey), serviceInstanceID.Str())
+	}
+
+	if val, ok := os.LookupEnv("OTEL_SERVICE_NAME"); ok {
+		r.Attributes().PutStr(string(semconv.ServiceNameKey), val)
+	}
+
+	for env, resourceAttribute := range envResourceMap {
+		if val, ok := os.LookupEnv(env); ok {
+			r.Attributes().PutStr(resourceAttribute, val)
+		}
+	}
+	if val, ok := os.LookupEnv("AWS_REGION"); ok {
+		r.Attributes().PutStr(string(semconv.CloudRegionKey), val)
+	}
+	if accountID := lambdalifecycle.GetAccountID(); accountID != "" {
+		r.Attributes().PutStr(string(semconv.CloudAccountIDKey), accountID)
+	}
+	if val, ok := os.LookupEnv("AWS_LAMBDA_LOG_GROUP_NAME"); ok {
+		r.Attributes().PutEmptySlice(string(semconv.AWSLogGroupNamesKey)).AppendEmpty().SetStr(val)
+	}
+	// The log stream identifies the execution environment.
+	if val, ok := os.LookupEnv("AWS_LAMBDA_LOG_STREAM_NAME"); ok {
+		r.Attributes().PutEmptySlice(string(semconv.AWSLogStreamNamesKey)).AppendEmpty().SetStr(val)
+		r.Attributes().PutStr(string(semconv.FaaSInstanceKey), val)
+	}
+	if val, ok := os.LookupEnv("AWS_EXECUTION_ENV"); ok {
+		r.Attributes().PutStr(string(semconv.ProcessRuntimeNameKey), strings.TrimPrefix(val, executionEnvPrefix))
+	}
+	return r
+}
+
+func hostArch() string {
+	if runtime.GOARCH == "arm64" {
+		return semconv.HostArchARM64.Value.AsString()
+	}
+	return semconv.HostArchAMD64.Value.AsString()
+}
+
+// functionResourceID returns the ARN identifying the function from the ARN it was invoked with. The invoked ARN
+// may be qualified with an alias, which is replaced by the function version, as the same execution environment
+// can be invoked through several aliases.
+func functionResourceID(invokedARN, version string) string {
+	// arn:partition:lambda:region:account-id:function:name[:qualifier]
+	parts := strings.Split(invokedARN, ":")
+	if len(parts) < 7 || parts[0] != "arn" || parts[5] != "function" {
+		return ""
+	}
+	resourceID := strings.Join(parts[:7], ":")
+	if version != "" {
+		resourceID += ":" + version
+	}
+	return resourceID
+}
+
+// updateResourceID adds the function's ARN to the resource once the function has been invoked, and the account
+// ID it contains if it was not known when the receiver was created.
+func (r *telemetryAPIReceiver) updateResourceID() {
+	attrs := r.resource.Attributes()
+	version, _ := attrs.Get(string(semconv.FaaSVersionKey))
+	resourceID := functionResourceID(lambdalifecycle.GetInvokedFunctionARN(), version.Str())
+	if resourceID == "" {
+		return
+	}
+	attrs.PutStr(string(semconv.CloudResourceIDKey), resourceID)
+	if _, ok := attrs.Get(string(semconv.CloudAccountIDKey)); !ok {
+		attrs.PutStr(string(semconv.CloudAccountIDKey), strings.Split(resourceID, ":")[4])
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-lambda/collector/lambdalifecycle"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+func setLifecycle(t *testing.T, accountID, invokedARN string) {
+	t.Helper()
+	lambdalifecycle.SetAccountID(accountID)
+	lambdalifecycle.SetInvokedFunctionARN(invokedARN)
+	t.Cleanup(func() {
+		lambdalifecycle.SetAccountID("")
+		lambdalifecycle.SetInvokedFunctionARN("")
+	})
+}
+
+func TestNewResource(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_FUNCTION_NAME", "my-function")
+	t.Setenv("AWS_LAMBDA_FUNCTION_VERSION", "$LATEST")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_LAMBDA_LOG_GROUP_NAME", "/aws/lambda/my-function")
+	t.Setenv("AWS_LAMBDA_LOG_STREAM_NAME", "2024/01/01/[$LATEST]0123456789abcdef")
+	t.Setenv("AWS_EXECUTION_ENV", "AWS_Lambda_python3.12")
+	setLifecycle(t, "123456789012", "")
+
+	attrs := newResource(receivertest.NewNopSettings(Type)).Attributes().AsRaw()
+	require.Equal(t, "aws", attrs[string(semconv.CloudProviderKey)])
+	require.Equal(t, "aws_lambda", attrs[string(semconv.CloudPlatformKey)])
+	require.Equal(t, "eu-west-1", attrs[string(semconv.CloudRegionKey)])
+	require.Equal(t, "eu-west-1", attrs[string(semconv.FaaSInvokedRegionKey)])
+	require.Equal(t, "123456789012", attrs[string(semconv.CloudAccountIDKey)])
+	require.Equal(t, "my-function", attrs[string(semconv.FaaSNameKey)])
+	require.Equal(t, []any{"/aws/lambda/my-function"}, attrs[string(semconv.AWSLogGroupNamesKey)])
+	require.Equal(t, []any{"2024/01/01/[$LATEST]0123456789abcdef"}, attrs[string(semconv.AWSLogStreamNamesKey)])
+	require.Equal(t, "2024/01/01/[$LATEST]0123456789abcdef", attrs[string(semconv.FaaSInstanceKey)])
+	require.Equal(t, "python3.12", attrs[string(semconv.ProcessRuntimeNameKey)])
+	if runtime.GOARCH == "arm64" {
+		require.Equal(t, "arm64", attrs[string(semconv.HostArchKey)])
+	} else {
+		require.Equal(t, "amd64", attrs[string(semconv.HostArchKey)])
+	}
+	require.NotContains(t, attrs, string(semconv.CloudResourceIDKey))
+}
+
+func TestNewResourceWithoutEnvironment(t *testing.T) {
+	setLifecycle(t, "", "")
+	attrs := newResource(receivertest.NewNopSettings(Type)).Attributes().AsRaw()
+	for _, key := range []string{
+		string(semconv.CloudAccountIDKey),
+		string(semconv.AWSLogGroupNamesKey),
+		string(semconv.AWSLogStreamNamesKey),
+		string(semconv.FaaSInstanceKey),
+		string(semconv.ProcessRuntimeNameKey),
+	} {
+		require.NotContains(t, attrs, key)
+	}
+}
+
+func TestFunctionResourceID(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		invokedARN string
+		version    string
+		expected   string
+	}{
+		{
+			desc:       "unqualified",
+			invokedARN: "arn:aws:lambda:eu-west-1:123456789012:function:my-function",
+			version:    "$LATEST",
+			expected:   "arn:aws:lambda:eu-west-1:123456789012:function:my-function:$LATEST",
+		},
+		{
+			desc:       "alias",
+			invokedARN: "arn:aws:lambda:eu-west-1:123456789012:function:my-function:live",
+			version:    "3",
+			expected:   "arn:aws:lambda:eu-west-1:123456789012:function:my-function:3",
+		},
+		{
+			desc:       "no version",
+			invokedARN: "arn:aws:lambda:eu-west-1:123456789012:function:my-function:live",
+			expected:   "arn:aws:lambda:eu-west-1:123456789012:function:my-function",
+		},
+		{
+			desc: "empty",
+		},
+		{
+			desc:       "not a function",
+			invokedARN: "arn:aws:s3:::my-bucket",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expected, functionResourceID(tc.invokedARN, tc.version))
+		})
+	}
+}
+
+func TestUpdateResourceID(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_FUNCTION_VERSION", "3")
+	setLifecycle(t, "", "")
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	r.updateResourceID()
+	require.NotContains(t, r.resource.Attributes().AsRaw(), string(semconv.CloudResourceIDKey))
+
+	lambdalifecycle.SetInvokedFunctionARN("arn:aws:lambda:eu-west-1:123456789012:function:my-function:live")
+	r.updateResourceID()
+	attrs := r.resource.Attributes().AsRaw()
+	require.Equal(t, "arn:aws:lambda:eu-west-1:123456789012:function:my-function:3", attrs[string(semconv.CloudResourceIDKey)])
+	require.Equal(t, "123456789012", attrs[string(semconv.CloudAccountIDKey)])
+}
@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"encoding/json"
+
+	"go.uber.org/zap"
+)
+
+// handleLines fans the newline-delimited events streamed on a connection by the Telemetry API out to the
+// receiver instances, one batch at a time. Unlike HTTP requests, a stream cannot be answered with a failure
+// status, so backpressure is not available.
+// As for httpHandler, logging besides the error cases is not recommended if extension logs are subscribed to.
+func (b *subscriptionBroker) handleLines(lines [][]byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	types := make([]string, len(lines))
+	events := make([]bool, len(lines))
+	for i, line := range lines {
+		types[i], events[i] = eventTypeOf(line)
+	}
+	for _, r := range b.receivers {
+		var selected [][]byte
+		for i, line := range lines {
+			// Lines that are not events are handed to every receiver, to be counted as unmarshal failures.
+			if !events[i] || delivers(r, types[i]) {
+				selected = append(selected, line)
+			}
+		}
+		if len(selected) > 0 {
+			r.handleLines(selected)
+		}
+	}
+}
+
+// handleLines processes a batch of events received as newline-delimited JSON.
+func (r *telemetryAPIReceiver) handleLines(lines [][]byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	slice := make([]event, 0, len(lines))
+	for _, line := range lines {
+		var el event
+		if err := json.Unmarshal(line, &el); err != nil {
+			r.logger.Error("error unmarshalling event", zap.Error(err))
+			r.telemetryMetrics.unmarshalFailuresMetric.Add(1)
+			continue
+		}
+		slice = append(slice, el)
+	}
+	r.markStateEvents(slice)
+	r.processEvents(slice, newRejectedRequest(0))
+}
@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+)
+
+// startTCPReceiver starts a receiver of function events streamed over TCP, subscribed through its own broker.
+func startTCPReceiver(t *testing.T) (*telemetryAPIReceiver, *consumertest.LogsSink, string) {
+	t.Helper()
+	startFakeTelemetryAPI(t)
+	b := &subscriptionBroker{}
+	r, logsSink := newBrokerReceiver(t, b, &Config{Types: []string{function}, Protocol: protocolTCP})
+	require.NoError(t, r.Start(context.Background(), nil))
+	return r, logsSink, b.address
+}
+
+func TestTCPHandler(t *testing.T) {
+	r, logsSink, address := startTCPReceiver(t)
+
+	conn, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte(`{"time":"2024-01-02T15:04:05.000Z","type":"function","record":"first"}` + "\n" +
+		`{"invalid": json}` + "\n"))
+	require.NoError(t, err)
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		require.Equal(c, 1, logsSink.LogRecordCount())
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = conn.Write([]byte(`{"time":"2024-01-02T15:04:05.001Z","type":"function","record":"second"}` + "\n"))
+	require.NoError(t, err)
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		require.Equal(c, 2, logsSink.LogRecordCount())
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, conn.Close())
+
+	require.NoError(t, r.Shutdown(context.Background()))
+	var bodies []string
+	for _, logs := range logsSink.AllLogs() {
+		bodies = append(bodies, logBodies(logs)...)
+	}
+	require.Equal(t, []string{"first", "second"}, bodies)
+}
+
+func TestTCPShutdownClosesConnections(t *testing.T) {
+	r, logsSink, address := startTCPReceiver(t)
+
+	conn, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte(`{"time":"2024-01-02T15:04:05.000Z","type":"function","record":"first"}` + "\n"))
+	require.NoError(t, err)
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		require.Equal(c, 1, logsSink.LogRecordCount())
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, r.Shutdown(context.Background()))
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	require.ErrorIs(t, err, io.EOF)
+
+	_, err = net.Dial("tcp", address)
+	require.Error(t, err)
+}
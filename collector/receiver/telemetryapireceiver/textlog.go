@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"regexp"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// textLogLine is a function log line in one of the default text formats of the Lambda runtimes.
+type textLogLine struct {
+	timestamp time.Time
+	requestID string
+	level     string
+	message   string
+}
+
+// textLogParser parses a plain text function log line, reporting whether the line has the parser's format.
+type textLogParser func(line string) (textLogLine, bool)
+
+const (
+	requestIDPattern = `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`
+	levelPattern     = `[A-Za-z]+`
+	javaTimeLayout   = "2006-01-02 15:04:05"
+)
+
+var (
+	// Node.js: 2024-01-02T15:04:05.000Z	<request id>	INFO	message
+	nodejsLogPattern = regexp.MustCompile(`(?s)^(\S+)\t(` + requestIDPattern + `)\t(` + levelPattern + `)\t(.*)$`)
+	// Python: [INFO]	2024-01-02T15:04:05.000Z	<request id>	message
+	pythonLogPattern = regexp.MustCompile(`(?s)^\[(` + levelPattern + `)\]\t(\S+)\t(` + requestIDPattern + `)\t(.*)$`)
+	// Java (aws-lambda-java-log4j2): 2024-01-02 15:04:05 <request id> INFO  Handler - message
+	javaLogPattern = regexp.MustCompile(`(?s)^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(?:[.,]\d+)?) (` + requestIDPattern + `) +(` + levelPattern + `) +(.*)$`)
+)
+
+// textLogParsers are the parsers selectable through the `text_log_format` setting.
+var textLogParsers = map[string]textLogParser{
+	textLogFormatNodejs: parseNodejsLog,
+	textLogFormatPython: parsePythonLog,
+	textLogFormatJava:   parseJavaLog,
+}
+
+// newTextLogParser returns the parser for the configured format. The automatic parser tries every format.
+// Lines are not parsed unless a format is configured.
+func newTextLogParser(format string) textLogParser {
+	switch format {
+	case "", textLogFormatNone:
+		return nil
+	case textLogFormatAuto:
+		return func(line string) (textLogLine, bool) {
+			for _, parse := range []textLogParser{parseNodejsLog, parsePythonLog, parseJavaLog} {
+				if parsed, ok := parse(line); ok {
+					return parsed, true
+				}
+			}
+			return textLogLine{}, false
+		}
+	default:
+		return textLogParsers[format]
+	}
+}
+
+func parseNodejsLog(line string) (textLogLine, bool) {
+	m := nodejsLogPattern.FindStringSubmatch(line)
+	if m == nil {
+		return textLogLine{}, false
+	}
+	return newTextLogLine(time.RFC3339, m[1], m[2], m[3], m[4])
+}
+
+func parsePythonLog(line string) (textLogLine, bool) {
+	m := pythonLogPattern.FindStringSubmatch(line)
+	if m == nil {
+		return textLogLine{}, false
+	}
+	return newTextLogLine(time.RFC3339, m[2], m[3], m[1], m[4])
+}
+
+func parseJavaLog(line string) (textLogLine, bool) {
+	m := javaLogPattern.FindStringSubmatch(line)
+	if m == nil {
+		return textLogLine{}, false
+	}
+	// Lambda runs in UTC. Fractional seconds are accepted by time.Parse although the layout omits them.
+	return newTextLogLine(javaTimeLayout, m[1], m[2], m[3], m[4])
+}
+
+// newTextLogLine validates the fields matched by a parser. Lines whose level is not a known severity are
+// not considered to have the parser's format.
+func newTextLogLine(timeLayout string, timestamp string, requestID string, level string, message string) (textLogLine, bool) {
+	t, err := time.Parse(timeLayout, timestamp)
+	if err != nil {
+		return textLogLine{}, false
+	}
+	if severityTextToNumber(level) == plog.SeverityNumberUnspecified {
+		return textLogLine{}, false
+	}
+	return textLogLine{timestamp: t, requestID: requestID, level: level, message: message}, true
+}
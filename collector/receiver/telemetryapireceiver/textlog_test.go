@@ -0,0 +1,145 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+func TestTextLogParsers(t *testing.T) {
+	const requestID = "8f507cfc-1b8a-4d7e-9d6f-0c3e8e1f2a3b"
+	timestamp := time.Date(2024, 1, 2, 15, 4, 5, 123000000, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		format   string
+		line     string
+		expected textLogLine
+		ok       bool
+	}{
+		{
+			desc:     "nodejs",
+			format:   textLogFormatNodejs,
+			line:     "2024-01-02T15:04:05.123Z\t" + requestID + "\tINFO\tHello\nworld",
+			expected: textLogLine{timestamp: timestamp, requestID: requestID, level: "INFO", message: "Hello\nworld"},
+			ok:       true,
+		},
+		{
+			desc:     "python",
+			format:   textLogFormatPython,
+			line:     "[WARNING]\t2024-01-02T15:04:05.123Z\t" + requestID + "\tDisk almost full",
+			expected: textLogLine{timestamp: timestamp, requestID: requestID, level: "WARNING", message: "Disk almost full"},
+			ok:       true,
+		},
+		{
+			desc:     "java",
+			format:   textLogFormatJava,
+			line:     "2024-01-02 15:04:05,123 " + requestID + " ERROR Handler:42 - Failed",
+			expected: textLogLine{timestamp: timestamp, requestID: requestID, level: "ERROR", message: "Handler:42 - Failed"},
+			ok:       true,
+		},
+		{
+			desc:     "auto detects python",
+			format:   textLogFormatAuto,
+			line:     "[ERROR]\t2024-01-02T15:04:05.123Z\t" + requestID + "\tBoom",
+			expected: textLogLine{timestamp: timestamp, requestID: requestID, level: "ERROR", message: "Boom"},
+			ok:       true,
+		},
+		{
+			desc:   "format mismatch",
+			format: textLogFormatNodejs,
+			line:   "[ERROR]\t2024-01-02T15:04:05.123Z\t" + requestID + "\tBoom",
+			ok:     false,
+		},
+		{
+			desc:   "unknown level",
+			format: textLogFormatAuto,
+			line:   "2024-01-02T15:04:05.123Z\t" + requestID + "\tHELLO\tworld",
+			ok:     false,
+		},
+		{
+			desc:   "unstructured line",
+			format: textLogFormatAuto,
+			line:   "Hello world",
+			ok:     false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			parsed, ok := newTextLogParser(tc.format)(tc.line)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+
+	require.Nil(t, newTextLogParser(""))
+	require.Nil(t, newTextLogParser(textLogFormatNone))
+}
+
+func TestCreateLogsTextLogFormat(t *testing.T) {
+	const requestID = "8f507cfc-1b8a-4d7e-9d6f-0c3e8e1f2a3b"
+	line := "2024-01-02T15:04:05.123Z\t" + requestID + "\tERROR\tSomething failed"
+
+	testCases := []struct {
+		desc             string
+		format           string
+		expectedBody     string
+		expectedSeverity plog.SeverityNumber
+		expectedID       string
+	}{
+		{
+			desc:             "kept by default",
+			expectedBody:     line,
+			expectedSeverity: plog.SeverityNumberUnspecified,
+		},
+		{
+			desc:             "auto",
+			format:           textLogFormatAuto,
+			expectedBody:     "Something failed",
+			expectedSeverity: plog.SeverityNumberError,
+			expectedID:       requestID,
+		},
+		{
+			desc:             "disabled",
+			format:           textLogFormatNone,
+			expectedBody:     line,
+			expectedSeverity: plog.SeverityNumberUnspecified,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := newTelemetryAPIReceiver(&Config{TextLogFormat: tc.format}, receivertest.NewNopSettings(Type))
+			require.NoError(t, err)
+
+			logs, err := r.createLogs([]event{{Time: "2024-01-02T15:04:06.000Z", Type: "function", Record: line}})
+			require.NoError(t, err)
+
+			record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			require.Equal(t, tc.expectedBody, record.Body().Str())
+			require.Equal(t, tc.expectedSeverity, record.SeverityNumber())
+			invocationID, _ := record.Attributes().Get(string(semconv.FaaSInvocationIDKey))
+			require.Equal(t, tc.expectedID, invocationID.Str())
+			if tc.expectedID != "" {
+				require.Equal(t, time.Date(2024, 1, 2, 15, 4, 5, 123000000, time.UTC), record.Timestamp().AsTime())
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-lambda/collector/internal/telemetryapi"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	tracingTypeXRay        = "X-Amzn-Trace-Id"
+	tracingTypeTraceparent = "traceparent"
+)
+
+// Fields of the trace context in function logs using the JSON log format.
+const (
+	logFieldTraceID     = "traceId"
+	logFieldSpanID      = "spanId"
+	logFieldXRayTraceID = "xray_trace_id"
+)
+
+// traceContext is the trace context the Lambda platform attached to an event through its `tracing` field.
+// See https://docs.aws.amazon.com/lambda/latest/dg/telemetry-schema-reference.html#TraceContext
+type traceContext struct {
+	traceID  pcommon.TraceID
+	parentID pcommon.SpanID
+	spanID   pcommon.SpanID
+	sampled  bool
+}
+
+// parseTracing extracts the trace context from the `tracing` field of a platform record.
+func parseTracing(record map[string]any) (traceContext, bool) {
+	tracing, ok := record["tracing"].(map[string]any)
+	if !ok {
+		return traceContext{}, false
+	}
+	value, _ := tracing["value"].(string)
+	tracingType, _ := tracing["type"].(string)
+
+	var tc traceContext
+	switch tracingType {
+	case tracingTypeTraceparent:
+		tc, ok = parseTraceparent(value)
+	default:
+		tc, ok = parseXRayTraceHeader(value)
+	}
+	if !ok {
+		return traceContext{}, false
+	}
+
+	if spanID, ok := tracing["spanId"].(string); ok {
+		tc.spanID, _ = parseSpanID(spanID)
+	}
+	return tc, true
+}
+
+// parseXRayTraceHeader parses an X-Ray header such as `Root=1-5e1b4151-43a0913a12345678901234f5;Parent=53995c3f42cd8ad8;Sampled=1`.
+// A missing Sampled key is treated as sampled, leaving the decision to the pipeline.
+func parseXRayTraceHeader(header string) (traceContext, bool) {
+	tc := traceContext{sampled: true}
+	hasRoot := false
+	for _, part := range strings.Split(header, ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "Root":
+			// Root=<version>-<epoch seconds>-<24 hex digits>
+			fields := strings.Split(value, "-")
+			if len(fields) != 3 || fields[0] != "1" {
+				return traceContext{}, false
+			}
+			traceID, ok := parseTraceID(fields[1] + fields[2])
+			if !ok {
+				return traceContext{}, false
+			}
+			tc.traceID = traceID
+			hasRoot = true
+		case "Parent":
+			tc.parentID, _ = parseSpanID(value)
+		case "Sampled":
+			tc.sampled = value != "0"
+		}
+	}
+	return tc, hasRoot
+}
+
+// parseTraceparent parses a W3C traceparent header such as `00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01`.
+// Versions after 00 may append fields, which are ignored, and version ff is invalid.
+// See https://www.w3.org/TR/trace-context/#traceparent-header
+func parseTraceparent(header string) (traceContext, bool) {
+	fields := strings.Split(strings.TrimSpace(header), "-")
+	if len(fields) < 4 || len(fields[0]) != 2 || len(fields[3]) != 2 {
+		return traceContext{}, false
+	}
+	version, err := hex.DecodeString(fields[0])
+	if err != nil || version[0] == 0xff || (version[0] == 0x00 && len(fields) != 4) {
+		return traceContext{}, false
+	}
+	traceID, ok := parseTraceID(fields[1])
+	if !ok {
+		return traceContext{}, false
+	}
+	parentID, ok := parseSpanID(fields[2])
+	if !ok {
+		return traceContext{}, false
+	}
+	flags, err := hex.DecodeString(fields[3])
+	if err != nil {
+		return traceContext{}, false
+	}
+	return traceContext{
+		traceID:  traceID,
+		parentID: parentID,
+		sampled:  flags[0]&0x01 == 0x01,
+	}, true
+}
+
+// parseLogTraceFields extracts the trace context from the trace fields of a JSON function log line.
+// The trace ID may be written as 32 hex digits, an X-Ray trace ID such as `1-5e1b4151-43a0913a12345678901234f5`
+// or a full X-Ray header.
+func parseLogTraceFields(record map[string]any) (traceContext, bool) {
+	value, ok := record[logFieldTraceID].(string)
+	if !ok {
+		value, ok = record[logFieldXRayTraceID].(string)
+	}
+	if !ok {
+		return traceContext{}, false
+	}
+
+	var tc traceContext
+	if tc.traceID, ok = parseTraceID(value); !ok {
+		header := value
+		if !strings.Contains(header, "Root=") {
+			header = "Root=" + header
+		}
+		if tc, ok = parseXRayTraceHeader(header); !ok {
+			return traceContext{}, false
+		}
+		// The parent of an X-Ray header is not the span that emitted the log, and a missing
+		// sampling decision is not a sampled trace here.
+		tc.parentID = pcommon.SpanID{}
+		tc.sampled = strings.Contains(header, "Sampled=1")
+	}
+	if spanID, ok := record[logFieldSpanID].(string); ok {
+		tc.spanID, _ = parseSpanID(spanID)
+	}
+	return tc, true
+}
+
+// rememberLogTraceContext keeps the trace context the platform attached to the invocation's events,
+// so that the invocation's logs can be correlated with its trace.
+func (r *telemetryAPIReceiver) rememberLogTraceContext(eventType string, record map[string]any, requestID string) {
+	if requestID == "" || eventType == string(telemetryapi.PlatformReport) {
+		return
+	}
+	if _, ok := r.logTraceContexts[requestID]; ok {
+		return
+	}
+	if tc, ok := parseTracing(record); ok {
+		r.logTraceContexts[requestID] = tc
+	}
+}
+
+// forgetLogTraceContext drops the trace context of the invocation once its platform.report event is processed.
+func (r *telemetryAPIReceiver) forgetLogTraceContext(el event) {
+	if el.Type != string(telemetryapi.PlatformReport) {
+		return
+	}
+	if record, ok := el.Record.(map[string]any); ok {
+		delete(r.logTraceContexts, r.getRecordRequestId(record))
+	}
+}
+
+// setLogTraceContext sets the trace context of the log record from the trace fields of a JSON log line,
+// falling back to the trace context of the invocation the log belongs to.
+func (r *telemetryAPIReceiver) setLogTraceContext(logRecord plog.LogRecord, record map[string]any, requestID string) {
+	tc, ok := r.logTraceContexts[requestID]
+	if logTC, logOK := parseLogTraceFields(record); logOK {
+		if ok && logTC.traceID == tc.traceID {
+			if logTC.spanID.IsEmpty() {
+				logTC.spanID = tc.spanID
+			}
+			logTC.sampled = tc.sampled
+		}
+		tc, ok = logTC, true
+	}
+	if !ok {
+		return
+	}
+
+	logRecord.SetTraceID(tc.traceID)
+	logRecord.SetSpanID(tc.spanID)
+	if tc.sampled {
+		logRecord.SetFlags(plog.DefaultLogRecordFlags.WithIsSampled(true))
+	}
+}
+
+func parseTraceID(s string) (pcommon.TraceID, bool) {
+	var tid pcommon.TraceID
+	if len(s) != hex.EncodedLen(len(tid)) {
+		return pcommon.TraceID{}, false
+	}
+	if _, err := hex.Decode(tid[:], []byte(s)); err != nil || tid.IsEmpty() {
+		return pcommon.TraceID{}, false
+	}
+	return tid, true
+}
+
+func parseSpanID(s string) (pcommon.SpanID, bool) {
+	var sid pcommon.SpanID
+	if len(s) != hex.EncodedLen(len(sid)) {
+		return pcommon.SpanID{}, false
+	}
+	if _, err := hex.Decode(sid[:], []byte(s)); err != nil || sid.IsEmpty() {
+		return pcommon.SpanID{}, false
+	}
+	return sid, true
+}
+
+// setSpanContext sets the span's identifiers from the trace context, generating the ones it lacks.
+// The key identifies the event the span is created from and seeds deterministic identifiers.
+func (r *telemetryAPIReceiver) setSpanContext(span ptrace.Span, tc *traceContext, key ...string) {
+	if tc == nil {
+		span.SetTraceID(r.traceIDFor(key...))
+		span.SetSpanID(r.spanIDFor(key...))
+		return
+	}
+	span.SetTraceID(tc.traceID)
+	span.SetParentSpanID(tc.parentID)
+	if tc.spanID.IsEmpty() {
+		span.SetSpanID(r.spanIDFor(key...))
+	} else {
+		span.SetSpanID(tc.spanID)
+	}
+}
+
+// traceIDFor returns a random trace ID, or one hashed from the key when deterministic IDs are enabled.
+func (r *telemetryAPIReceiver) traceIDFor(key ...string) pcommon.TraceID {
+	if !r.deterministicIDs {
+		return newTraceID()
+	}
+	var tid pcommon.TraceID
+	copy(tid[:], hashKey("trace", key))
+	return tid
+}
+
+// spanIDFor returns a random span ID, or one hashed from the key when deterministic IDs are enabled.
+func (r *telemetryAPIReceiver) spanIDFor(key ...string) pcommon.SpanID {
+	if !r.deterministicIDs {
+		return newSpanID()
+	}
+	var sid pcommon.SpanID
+	copy(sid[:], hashKey("span", key))
+	return sid
+}
+
+func hashKey(kind string, key []string) []byte {
+	h := sha256.New()
+	h.Write([]byte(kind))
+	for _, k := range key {
+		h.Write([]byte{0})
+		h.Write([]byte(k))
+	}
+	return h.Sum(nil)
+}
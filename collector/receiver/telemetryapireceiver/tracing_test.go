@@ -0,0 +1,371 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetryapireceiver // import "github.com/open-telemetry/opentelemetry-lambda/collector/receiver/telemetryapireceiver"
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func TestParseTracing(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		record   map[string]any
+		expected traceContext
+		ok       bool
+	}{
+		{
+			desc:   "no tracing",
+			record: map[string]any{},
+			ok:     false,
+		},
+		{
+			desc: "x-ray header sampled",
+			record: map[string]any{
+				"tracing": map[string]any{
+					"spanId": "3a6fd4da3b5f2d78",
+					"type":   "X-Amzn-Trace-Id",
+					"value":  "Root=1-5e1b4151-43a0913a12345678901234f5;Parent=53995c3f42cd8ad8;Sampled=1",
+				},
+			},
+			expected: traceContext{
+				traceID:  pcommon.TraceID{0x5e, 0x1b, 0x41, 0x51, 0x43, 0xa0, 0x91, 0x3a, 0x12, 0x34, 0x56, 0x78, 0x90, 0x12, 0x34, 0xf5},
+				parentID: pcommon.SpanID{0x53, 0x99, 0x5c, 0x3f, 0x42, 0xcd, 0x8a, 0xd8},
+				spanID:   pcommon.SpanID{0x3a, 0x6f, 0xd4, 0xda, 0x3b, 0x5f, 0x2d, 0x78},
+				sampled:  true,
+			},
+			ok: true,
+		},
+		{
+			desc: "x-ray header not sampled",
+			record: map[string]any{
+				"tracing": map[string]any{
+					"type":  "X-Amzn-Trace-Id",
+					"value": "Root=1-5e1b4151-43a0913a12345678901234f5;Parent=53995c3f42cd8ad8;Sampled=0",
+				},
+			},
+			expected: traceContext{
+				traceID:  pcommon.TraceID{0x5e, 0x1b, 0x41, 0x51, 0x43, 0xa0, 0x91, 0x3a, 0x12, 0x34, 0x56, 0x78, 0x90, 0x12, 0x34, 0xf5},
+				parentID: pcommon.SpanID{0x53, 0x99, 0x5c, 0x3f, 0x42, 0xcd, 0x8a, 0xd8},
+				sampled:  false,
+			},
+			ok: true,
+		},
+		{
+			desc: "x-ray header without root",
+			record: map[string]any{
+				"tracing": map[string]any{
+					"type":  "X-Amzn-Trace-Id",
+					"value": "Parent=53995c3f42cd8ad8;Sampled=1",
+				},
+			},
+			ok: false,
+		},
+		{
+			desc: "x-ray header with malformed root",
+			record: map[string]any{
+				"tracing": map[string]any{
+					"type":  "X-Amzn-Trace-Id",
+					"value": "Root=1-zz;Parent=53995c3f42cd8ad8",
+				},
+			},
+			ok: false,
+		},
+		{
+			desc: "traceparent",
+			record: map[string]any{
+				"tracing": map[string]any{
+					"type":  "traceparent",
+					"value": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+				},
+			},
+			expected: traceContext{
+				traceID:  pcommon.TraceID{0x0a, 0xf7, 0x65, 0x19, 0x16, 0xcd, 0x43, 0xdd, 0x84, 0x48, 0xeb, 0x21, 0x1c, 0x80, 0x31, 0x9c},
+				parentID: pcommon.SpanID{0xb7, 0xad, 0x6b, 0x71, 0x69, 0x20, 0x33, 0x31},
+				sampled:  true,
+			},
+			ok: true,
+		},
+		{
+			desc: "traceparent not sampled",
+			record: map[string]any{
+				"tracing": map[string]any{
+					"type":  "traceparent",
+					"value": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00",
+				},
+			},
+			expected: traceContext{
+				traceID:  pcommon.TraceID{0x0a, 0xf7, 0x65, 0x19, 0x16, 0xcd, 0x43, 0xdd, 0x84, 0x48, 0xeb, 0x21, 0x1c, 0x80, 0x31, 0x9c},
+				parentID: pcommon.SpanID{0xb7, 0xad, 0x6b, 0x71, 0x69, 0x20, 0x33, 0x31},
+				sampled:  false,
+			},
+			ok: true,
+		},
+		{
+			desc: "traceparent with invalid version",
+			record: map[string]any{
+				"tracing": map[string]any{
+					"type":  "traceparent",
+					"value": "ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+				},
+			},
+			ok: false,
+		},
+		{
+			desc: "traceparent version 00 with extra field",
+			record: map[string]any{
+				"tracing": map[string]any{
+					"type":  "traceparent",
+					"value": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-extra",
+				},
+			},
+			ok: false,
+		},
+		{
+			desc: "traceparent future version with extra field",
+			record: map[string]any{
+				"tracing": map[string]any{
+					"type":  "traceparent",
+					"value": "01-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-extra",
+				},
+			},
+			expected: traceContext{
+				traceID:  pcommon.TraceID{0x0a, 0xf7, 0x65, 0x19, 0x16, 0xcd, 0x43, 0xdd, 0x84, 0x48, 0xeb, 0x21, 0x1c, 0x80, 0x31, 0x9c},
+				parentID: pcommon.SpanID{0xb7, 0xad, 0x6b, 0x71, 0x69, 0x20, 0x33, 0x31},
+				sampled:  true,
+			},
+			ok: true,
+		},
+		{
+			desc: "traceparent with zero parent id",
+			record: map[string]any{
+				"tracing": map[string]any{
+					"type":  "traceparent",
+					"value": "00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01",
+				},
+			},
+			ok: false,
+		},
+		{
+			desc: "traceparent with zero trace id",
+			record: map[string]any{
+				"tracing": map[string]any{
+					"type":  "traceparent",
+					"value": "00-00000000000000000000000000000000-b7ad6b7169203331-01",
+				},
+			},
+			ok: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			actual, ok := parseTracing(tc.record)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestDeterministicIDs(t *testing.T) {
+	body := `[
+		{"time":"2006-01-02T15:04:04.000Z", "type":"platform.initStart", "record": {}},
+		{"time":"2006-01-02T15:04:05.000Z", "type":"platform.initRuntimeDone", "record": {"status":"success"}},
+		{"time":"2006-01-02T15:04:05.000Z", "type":"platform.start", "record": {"requestId":"test-id"}},
+		{"time":"2006-01-02T15:04:06.000Z", "type":"platform.runtimeDone", "record": {"requestId":"test-id", "status":"success",
+			"spans": [{"name": "responseLatency", "start": "2006-01-02T15:04:05.100Z", "durationMs": 10}]}},
+		{"time":"2006-01-02T15:04:06.000Z", "type":"platform.report", "record": {"requestId":"test-id", "status":"success"}}
+	]`
+
+	deliver := func(idGeneration string) []ptrace.Span {
+		r, err := newTelemetryAPIReceiver(&Config{IDGeneration: idGeneration}, receivertest.NewNopSettings(Type))
+		require.NoError(t, err)
+		sink := &consumertest.TracesSink{}
+		r.registerTracesConsumer(sink)
+		r.httpHandler(httptest.NewRecorder(), httptest.NewRequest("POST", "http://localhost/", strings.NewReader(body)))
+
+		var spans []ptrace.Span
+		for _, td := range sink.AllTraces() {
+			ss := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+			for i := 0; i < ss.Len(); i++ {
+				spans = append(spans, ss.At(i))
+			}
+		}
+		require.Len(t, spans, 3)
+		return spans
+	}
+
+	t.Run("deterministic", func(t *testing.T) {
+		first := deliver(idGenerationDeterministic)
+		replayed := deliver(idGenerationDeterministic)
+		for i := range first {
+			require.Equal(t, first[i].TraceID(), replayed[i].TraceID())
+			require.Equal(t, first[i].SpanID(), replayed[i].SpanID())
+			require.Equal(t, first[i].ParentSpanID(), replayed[i].ParentSpanID())
+		}
+		require.NotEqual(t, first[0].TraceID(), first[1].TraceID())
+		require.Equal(t, first[1].SpanID(), first[2].ParentSpanID())
+	})
+
+	t.Run("random", func(t *testing.T) {
+		first := deliver(idGenerationRandom)
+		replayed := deliver(idGenerationRandom)
+		for i := range first {
+			require.NotEqual(t, first[i].SpanID(), replayed[i].SpanID())
+		}
+	})
+}
+
+func TestParseLogTraceFields(t *testing.T) {
+	traceID := pcommon.TraceID{0x5e, 0x1b, 0x41, 0x51, 0x43, 0xa0, 0x91, 0x3a, 0x12, 0x34, 0x56, 0x78, 0x90, 0x12, 0x34, 0xf5}
+	spanID := pcommon.SpanID{0x3a, 0x6f, 0xd4, 0xda, 0x3b, 0x5f, 0x2d, 0x78}
+
+	testCases := []struct {
+		desc     string
+		record   map[string]any
+		expected traceContext
+		ok       bool
+	}{
+		{
+			desc:   "no trace fields",
+			record: map[string]any{"message": "hello"},
+			ok:     false,
+		},
+		{
+			desc:     "hex trace id and span id",
+			record:   map[string]any{"traceId": "5e1b415143a0913a12345678901234f5", "spanId": "3a6fd4da3b5f2d78"},
+			expected: traceContext{traceID: traceID, spanID: spanID},
+			ok:       true,
+		},
+		{
+			desc:     "x-ray trace id",
+			record:   map[string]any{"xray_trace_id": "1-5e1b4151-43a0913a12345678901234f5"},
+			expected: traceContext{traceID: traceID},
+			ok:       true,
+		},
+		{
+			desc:     "x-ray header",
+			record:   map[string]any{"traceId": "Root=1-5e1b4151-43a0913a12345678901234f5;Parent=53995c3f42cd8ad8;Sampled=1"},
+			expected: traceContext{traceID: traceID, sampled: true},
+			ok:       true,
+		},
+		{
+			desc:   "malformed trace id",
+			record: map[string]any{"traceId": "not-a-trace"},
+			ok:     false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			actual, ok := parseLogTraceFields(tc.record)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestCreateLogsTraceContext(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{LogReport: true}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	logs, err := r.createLogs([]event{
+		{
+			Time: "2022-10-12T00:03:50.000Z",
+			Type: "platform.start",
+			Record: map[string]any{
+				"requestId": "test-id",
+				"tracing": map[string]any{
+					"spanId": "3a6fd4da3b5f2d78",
+					"type":   "X-Amzn-Trace-Id",
+					"value":  "Root=1-5e1b4151-43a0913a12345678901234f5;Parent=53995c3f42cd8ad8;Sampled=1",
+				},
+			},
+		},
+		{Time: "2022-10-12T00:03:50.001Z", Type: "function", Record: "plain text line"},
+		{
+			Time: "2022-10-12T00:03:50.002Z",
+			Type: "function",
+			Record: map[string]any{
+				"message": "json line with its own span",
+				"traceId": "5e1b415143a0913a12345678901234f5",
+				"spanId":  "b7ad6b7169203331",
+			},
+		},
+		{
+			Time:   "2022-10-12T00:03:50.003Z",
+			Type:   "function",
+			Record: map[string]any{"message": "json line of another trace", "xray_trace_id": "1-0af76519-16cd43dd8448eb211c80319c"},
+		},
+		{Time: "2022-10-12T00:03:50.004Z", Type: "platform.runtimeDone", Record: map[string]any{"requestId": "test-id", "status": "success"}},
+		{Time: "2022-10-12T00:03:50.005Z", Type: "platform.report", Record: map[string]any{"requestId": "test-id", "status": "success"}},
+	})
+	require.NoError(t, err)
+	require.Empty(t, r.logTraceContexts)
+
+	records := logRecords(t, logs)
+	require.Equal(t, 6, records.Len())
+	expected := []struct {
+		traceID string
+		spanID  string
+		sampled bool
+	}{
+		{"5e1b415143a0913a12345678901234f5", "3a6fd4da3b5f2d78", true},
+		{"5e1b415143a0913a12345678901234f5", "3a6fd4da3b5f2d78", true},
+		{"5e1b415143a0913a12345678901234f5", "b7ad6b7169203331", true},
+		{"0af7651916cd43dd8448eb211c80319c", "", false},
+		{"5e1b415143a0913a12345678901234f5", "3a6fd4da3b5f2d78", true},
+		{"5e1b415143a0913a12345678901234f5", "3a6fd4da3b5f2d78", true},
+	}
+	for i, e := range expected {
+		record := records.At(i)
+		require.Equal(t, e.traceID, record.TraceID().String(), "record %d", i)
+		require.Equal(t, e.spanID, record.SpanID().String(), "record %d", i)
+		require.Equal(t, e.sampled, record.Flags().IsSampled(), "record %d", i)
+	}
+
+	// The trace context is forgotten once the invocation is reported.
+	logs, err = r.createLogs([]event{{Time: "2022-10-12T00:03:51.000Z", Type: "function", Record: "late line"}})
+	require.NoError(t, err)
+	require.Equal(t, plog.LogRecordFlags(0), logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Flags())
+	require.True(t, logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).TraceID().IsEmpty())
+}
+
+func TestExpireLogTraceContexts(t *testing.T) {
+	r, err := newTelemetryAPIReceiver(&Config{}, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+	now := time.Now()
+
+	// The platform.report event of "test-id" never arrives.
+	r.rememberLogTraceContext("platform.start", map[string]any{
+		"requestId": "test-id",
+		"tracing": map[string]any{
+			"spanId": "3a6fd4da3b5f2d78",
+			"type":   "X-Amzn-Trace-Id",
+			"value":  "Root=1-5e1b4151-43a0913a12345678901234f5;Parent=53995c3f42cd8ad8;Sampled=1",
+		},
+	}, "test-id")
+	r.expireInvocations(now)
+	require.Contains(t, r.logTraceContexts, "test-id")
+	r.expireInvocations(now.Add(maxInvocationAge))
+	require.Empty(t, r.logTraceContexts)
+}
@@ -18,4 +18,12 @@ type event struct {
 	Time   string `json:"time"`
 	Type   string `json:"type"`
 	Record any    `json:"record"`
+
+	// requestID is the invocation the event is attributed to when its record does not carry a request ID.
+	requestID string
+	// emf reports whether metrics were extracted from the event's Embedded Metric Format record.
+	emf bool
+	// stateOnly reports whether the event is only tracked, as a platform event handed to a receiver not subscribed
+	// to platform events.
+	stateOnly bool
 }